@@ -3,70 +3,288 @@ package config
 import (
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 )
 
 type RedisInstanceConfig struct {
-	Name     string     `yaml:"name"`     // 实例名称
-	Addr     string     `yaml:"addr"`     // 地址
-	Port     int        `yaml:"port"`     // 端口
-	Password string     `yaml:"password"` // 密码
-	DBs      []DBConfig `yaml:"dbs"`      // 数据库
+	Name     string       `yaml:"name" json:"name"`         // 实例名称
+	Mode     string       `yaml:"mode" json:"mode"`         // 模式 standalone、sentinel、cluster, 默认 standalone
+	Init     string       `yaml:"init" json:"init"`         // 初始化策略 eager(启动时连接, 失败则退出)、lazy(首次使用时连接)、optional(失败时降级启动并后台重试), 默认 eager
+	Addr     string       `yaml:"addr" json:"addr"`         // standalone: 地址
+	Port     int          `yaml:"port" json:"port"`         // standalone: 端口
+	Username string       `yaml:"username" json:"username"` // ACL 用户名, 为空时使用 default 用户
+	Password Secret       `yaml:"password" json:"password"` // 密码, 支持 file:、env: 引用
+	TLS      RedisTLS     `yaml:"tls" json:"tls"`           // TLS 连接
+	Breaker  RedisBreaker `yaml:"breaker" json:"breaker"`   // 熔断, 对实例下每个 db 的客户端分别生效
+	DBs      []DBConfig   `yaml:"dbs" json:"dbs"`           // 数据库, cluster 模式只支持 db 0
+
+	MasterName       string   `yaml:"masterName" json:"mastername"`             // sentinel: 主节点名称
+	SentinelAddrs    []string `yaml:"sentinelAddrs" json:"sentineladdrs"`       // sentinel: 哨兵地址 host:port
+	SentinelPassword Secret   `yaml:"sentinelPassword" json:"sentinelpassword"` // sentinel: 哨兵密码, 支持 file:、env: 引用
+	ClusterAddrs     []string `yaml:"clusterAddrs" json:"clusteraddrs"`         // cluster: 种子节点 host:port
+}
+
+type RedisTLS struct {
+	Enabled            bool   `yaml:"enabled" json:"enabled"`                       // 是否开启
+	CAFile             string `yaml:"caFile" json:"cafile"`                         // CA 证书, 为空时使用系统证书
+	CertFile           string `yaml:"certFile" json:"certfile"`                     // 客户端证书, 双向认证时配置
+	KeyFile            string `yaml:"keyFile" json:"keyfile"`                       // 客户端私钥
+	ServerName         string `yaml:"serverName" json:"servername"`                 // 校验的服务端名称, 为空时使用连接地址
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify" json:"insecureskipverify"` // 跳过证书校验, 仅用于开发环境
+}
+
+type RedisBreaker struct {
+	Enabled       bool `yaml:"enabled" json:"enabled"`             // 是否开启
+	Failures      int  `yaml:"failures" json:"failures"`           // 连续失败多少次后熔断, 默认5
+	SlowThreshold int  `yaml:"slowThreshold" json:"slowthreshold"` // 慢调用阈值(毫秒), 超过视为失败, 为0时不统计
+	OpenTimeout   int  `yaml:"openTimeout" json:"opentimeout"`     // 熔断持续时间(毫秒), 之后进入半开状态放行探测, 默认10000
+	Probes        int  `yaml:"probes" json:"probes"`               // 半开状态放行的探测次数, 全部成功后恢复, 默认1
 }
 
 type DBConfig struct {
-	DB       int `yaml:"db"`
-	PoolSize int `yaml:"pool_size"`
+	DB       int `yaml:"db" json:"db"`
+	PoolSize int `yaml:"pool_size" mapstructure:"pool_size" json:"pool_size"` // 最大连接数, 为0时使用 go-redis 默认值(10*CPU核数)
+
+	MinIdleConns    int `yaml:"min_idle_conns" mapstructure:"min_idle_conns" json:"min_idle_conns"`             // 最小空闲连接数, 默认0, 空闲时连接池缩减到1个连接
+	MaxIdleConns    int `yaml:"max_idle_conns" mapstructure:"max_idle_conns" json:"max_idle_conns"`             // 最大空闲连接数, 为0时不限制
+	ConnMaxIdleTime int `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time" json:"conn_max_idle_time"` // 空闲连接保留时间(秒), 超时的连接由后台定期回收, 为0时使用 go-redis 默认值(30分钟), -1 不关闭
+	DialTimeout     int `yaml:"dial_timeout" mapstructure:"dial_timeout" json:"dial_timeout"`                   // 连接超时(毫秒), 默认5000
+	ReadTimeout     int `yaml:"read_timeout" mapstructure:"read_timeout" json:"read_timeout"`                   // 读超时(毫秒), 默认10000, -1 不超时
+	WriteTimeout    int `yaml:"write_timeout" mapstructure:"write_timeout" json:"write_timeout"`                // 写超时(毫秒), 默认10000, -1 不超时
+	MaxRetries      int `yaml:"max_retries" mapstructure:"max_retries" json:"max_retries"`                      // 最大重试次数, 默认5, -1 不重试
 }
 
 type Zap struct {
-	Director   string // 日志文件夹
-	Level      string // 日志级别
-	MaxAge     int    // 日志保存天数
-	MaxSize    int    // 日志大小(MB)
-	MaxBackups int    // 日志备份数量
-	Format     string // 输出日志格式
+	Director   string `json:"director"`   // 日志文件夹
+	Level      string `json:"level"`      // 日志级别
+	MaxAge     int    `json:"maxage"`     // 日志保存天数
+	MaxSize    int    `json:"maxsize"`    // 日志大小(MB)
+	MaxBackups int    `json:"maxbackups"` // 日志备份数量
+	Format     string `json:"format"`     // 输出日志格式
+
+	RevertMinutes int `json:"revertminutes"` // 通过信号临时调整日志级别后自动恢复的分钟数, 0 表示不自动恢复
+
+	Sinks    []Sink   `json:"sinks"`    // 日志输出, 为空时全部写入 service.log
+	Redact   Redact   `json:"redact"`   // 日志脱敏
+	Sampling Sampling `json:"sampling"` // 日志采样
+	Async    Async    `json:"async"`    // 异步写入
+}
+
+type Async struct {
+	Enabled       bool   `json:"enabled"`       // 是否开启, tcp、udp、syslog 输出本身即为异步
+	BufferSize    int    `json:"buffersize"`    // 缓冲条数, 默认4096
+	FlushInterval int    `json:"flushinterval"` // 刷新间隔(毫秒), 默认1000
+	Overflow      string `json:"overflow"`      // 缓冲写满时的处理方式 block(阻塞等待)、drop(丢弃并计数), 默认 block
+}
+
+type Sampling struct {
+	Initial        int `json:"initial"`        // 每秒每条消息先记录的条数, 为0时不采样
+	Thereafter     int `json:"thereafter"`     // 超出后每N条记录一条, 为0时丢弃超出部分
+	ReportInterval int `json:"reportinterval"` // 汇总采样和缓冲写满丢弃条数的间隔(秒), 默认60
+}
+
+type Redact struct {
+	Headers  []string `json:"headers"`  // 请求头, 忽略大小写
+	Fields   []string `json:"fields"`   // 字段名, 忽略大小写; 不含 . 时匹配任意层级的同名字段, 含 . 时从日志字段名开始匹配, 如 request.password
+	Patterns []string `json:"patterns"` // 正则, 匹配内容替换为 ******
+}
+
+type Sink struct {
+	Type       string   `json:"type"`       // 输出类型 file、stdout、stderr、syslog、tcp、udp, 默认 file
+	Filename   string   `json:"filename"`   // file: 文件名, 位于日志文件夹下
+	Address    string   `json:"address"`    // tcp、udp: 采集端地址 host:port; syslog: 远程 syslog 地址
+	Network    string   `json:"network"`    // syslog: 连接方式 tcp、udp, 为空时写入本机 syslog
+	Tag        string   `json:"tag"`        // syslog: 标识
+	BufferSize int      `json:"buffersize"` // tcp、udp、syslog: 缓冲条数, 写满后丢弃, 默认1024
+	Format     string   `json:"format"`     // 输出日志格式, 为空时沿用zap配置, tcp、udp 默认 json
+	Categories []string `json:"categories"` // 日志分类(app、access、error), 为空表示全部分类
+	Level      string   `json:"level"`      // 最低日志级别, 为空表示沿用全局级别
+	MaxAge     int      `json:"maxage"`     // file: 日志保存天数, 为0时沿用zap配置
+	MaxSize    int      `json:"maxsize"`    // file: 日志大小(MB), 为0时沿用zap配置
+	MaxBackups int      `json:"maxbackups"` // file: 日志备份数量, 为0时沿用zap配置
 }
 
 type Config struct {
-	Debug        string   // 调试模式
-	Port         int      // 端口
-	Limit        float64  // 限流
-	AllowOrigins []string `yaml:"allowOrigins"` // 允许跨域origin
+	Debug        string   `json:"debug"`                            // 调试模式
+	Port         int      `json:"port"`                             // 端口
+	Limit        float64  `json:"limit"`                            // 限流
+	AllowOrigins []string `yaml:"allowOrigins" json:"alloworigins"` // 允许跨域origin
 	Redis        struct { // Redis配置
-		Instances []RedisInstanceConfig // Redis实例配置
-	}
-	Zap Zap // 日志
+		Instances []RedisInstanceConfig `json:"instances"` // Redis实例配置
+	} `json:"redis"`
+	Zap      Zap      `json:"zap"`      // 日志
+	Auth     Auth     `json:"auth"`     // 鉴权
+	Admin    Admin    `json:"admin"`    // 管理接口
+	Trace    Trace    `json:"trace"`    // 链路跟踪
+	Tracing  Tracing  `json:"tracing"`  // 链路追踪导出
+	Shutdown Shutdown `json:"shutdown"` // 优雅关闭
+
+	sources map[string]string // 配置项来源
+}
+
+type Auth struct {
+	ClientID Secret `yaml:"clientId" json:"clientid"` // 接口调用方ID, 支持 file:、env: 引用
+}
+
+type Trace struct {
+	Header         string `json:"header"`         // 兼容的链路ID请求头, 没有 traceparent 时沿用, 如 X-Request-Id
+	ResponseHeader string `json:"responseheader"` // 回传链路ID的响应头, 为空时不回传
+}
+
+type Tracing struct {
+	Enabled     bool    `json:"enabled"`     // 是否开启
+	ServiceName string  `json:"servicename"` // 服务名, 默认 service
+	Exporter    string  `json:"exporter"`    // 导出器 otlp、stdout、file
+	Endpoint    string  `json:"endpoint"`    // otlp: 采集端地址 host:port
+	Insecure    bool    `json:"insecure"`    // otlp: 使用 HTTP 而非 HTTPS
+	File        string  `json:"file"`        // file: 导出文件路径
+	SampleRatio float64 `json:"sampleratio"` // 采样比例 0-1, 为0时全部采样, 上游已决定采样时沿用上游
+}
+
+type Shutdown struct {
+	Timeout int `json:"timeout"` // 等待请求完成的超时时间(秒), 默认30
+	Delay   int `json:"delay"`   // 就绪检查返回不可用后等待的秒数, 让负载均衡先撤走流量, 默认5, 为0时不等待
+}
+
+type Admin struct {
+	Token  Secret `yaml:"token" json:"token"` // 管理接口令牌, 为空时禁用管理接口, 支持 file:、env: 引用
+	Port   int    `json:"port"`               // 管理端口, 提供 /metrics、/debug 等运维接口, 为0时不开启
+	Socket string `json:"socket"`             // 管理端口改为监听 unix socket, 优先于 Port
 }
 
-const configFilePath = "./config.yaml"
+// 配置按以下顺序合并, 后者覆盖前者:
+//  0. 默认值, 见 defaultSettings
+//  1. 基础配置文件, 默认 ./config.yaml, 可通过 -config 或 SERVICE_CONFIG 指定
+//  2. 环境配置文件, 与基础配置文件同目录的 config.<profile>.yaml, profile 通过 -profile 或 SERVICE_PROFILE 指定
+//  3. 环境变量, 前缀 SERVICE_, 层级与数组下标用 _ 连接, 如 SERVICE_REDIS_INSTANCES_0_PASSWORD
+//  4. 命令行参数, 如 -port
+//
+// 环境配置文件中的 map 会与基础配置逐层合并, 数组则整体替换.
+const (
+	defaultConfigFile = "./config.yaml"
+	envPrefix         = "SERVICE_"
+	envConfigFile     = envPrefix + "CONFIG"
+	envProfile        = envPrefix + "PROFILE"
+)
 
 var (
-	AppConfig = &Config{}
-	port      int
+	appConfig  atomic.Pointer[Config]
+	port       int
+	configFile string
+	profile    string
 )
 
-func InitConfig() error {
-	// 设置命令行参数
-	flag.IntVar(&port, "port", 0, "指定端口号")
-	flag.Parse()
-
-	// 读取配置文件
-	viper.SetConfigFile(configFilePath)
-	if err := viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("配置文件读取错误: %w", err)
-	}
+// Get 获取当前生效的配置, 热加载时整体替换, 调用方不要修改返回值
+func Get() *Config {
+	return appConfig.Load()
+}
 
-	// 配置转结构体
-	if err := viper.Unmarshal(&AppConfig); err != nil {
-		return fmt.Errorf("配置解码失败: %w", err)
+// BindFlags 注册配置相关的命令行参数, 需在 InitConfig 之前解析
+func BindFlags(fs *flag.FlagSet) {
+	fs.IntVar(&port, "port", 0, "指定端口号")
+	fs.StringVar(&configFile, "config", envOr(envConfigFile, defaultConfigFile), "指定配置文件路径")
+	fs.StringVar(&profile, "profile", os.Getenv(envProfile), "指定环境配置, 如 dev、prod")
+}
+
+func InitConfig() error {
+	cfg, err := load()
+	if err != nil {
+		return err
 	}
+	appConfig.Store(cfg)
 
 	// 如果命令行参数中有指定端口，则更新配置文件中的端口
 	if port != 0 {
-		AppConfig.Port = port
 		fmt.Println(fmt.Sprintf("使用命令行设置的端口:%v", port))
 	}
 	return nil
 }
+
+// load 按合并顺序读取配置并生成新的配置
+func load() (*Config, error) {
+	settings := make(map[string]any)
+	sources := make(map[string]string)
+	mergeSettings(settings, defaultSettings(), sources, "", SourceDefault)
+
+	// 依次合并基础配置文件和环境配置文件
+	for _, file := range configFiles() {
+		fileSettings, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		mergeSettings(settings, fileSettings, sources, "", "file:"+file)
+	}
+
+	// 环境变量覆盖, 无法匹配配置项的变量只提示, 不影响启动
+	if ignored := applyEnv(settings, sources, os.Environ()); len(ignored) > 0 {
+		fmt.Printf("忽略无法匹配配置项的环境变量: %s\n", strings.Join(ignored, ", "))
+	}
+	merged := viper.New()
+	if err := merged.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("配置合并失败: %w", err)
+	}
+
+	// 配置转结构体
+	cfg := &Config{sources: sources}
+	if err := merged.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("配置解码失败: %w", err)
+	}
+
+	// 命令行参数优先于配置文件
+	if port != 0 {
+		cfg.Port = port
+		sources["port"] = SourceFlag
+	}
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, fmt.Errorf("配置密钥解析失败:\n%w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Check 按启动流程加载并校验配置, 不影响当前生效的配置
+func Check() error {
+	_, err := load()
+	return err
+}
+
+// readFile 读取单个配置文件
+func readFile(file string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 读取错误: %w", file, err)
+	}
+	return v.AllSettings(), nil
+}
+
+// configFiles 返回参与合并的配置文件
+func configFiles() []string {
+	files := []string{configFile}
+	if profile != "" {
+		files = append(files, profileFile(configFile, profile))
+	}
+	return files
+}
+
+// profileFile 根据基础配置文件生成环境配置文件路径, 如 ./config.yaml -> ./config.prod.yaml
+func profileFile(base, profile string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext
+}
+
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const baseYAML = `
+debug: release
+port: 8080
+limit: 10
+auth:
+  clientId: base-client
+redis:
+  instances:
+    - name: default
+      addr: 127.0.0.1
+      port: 6379
+      password: base-pw
+      dbs:
+        - db: 0
+          pool_size: 10
+        - db: 1
+          pool_size: 5
+`
+
+// setupFiles 写入基础配置和环境配置, 并设置 -config、-profile, 测试结束后恢复
+func setupFiles(t *testing.T, base, overlay string) {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte(base), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldFile, oldProfile, oldPort := configFile, profile, port
+	t.Cleanup(func() { configFile, profile, port = oldFile, oldProfile, oldPort })
+	configFile, profile, port = file, "", 0
+	if overlay != "" {
+		profile = "test"
+		if err := os.WriteFile(profileFile(file, profile), []byte(overlay), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadMergeOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlay string
+		env     map[string]string
+		port    int
+		check   func(t *testing.T, cfg *Config)
+		sources map[string]string
+	}{
+		{
+			name: "基础配置",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Port, 8080)
+				assertEqual(t, cfg.Zap.Director, "log") // 默认值
+			},
+			sources: map[string]string{"port": "file:", "zap.director": SourceDefault},
+		},
+		{
+			name:    "环境配置覆盖基础配置, map 逐层合并",
+			overlay: "port: 9090\nauth:\n  clientId: profile-client\n",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Port, 9090)
+				assertEqual(t, cfg.Limit, 10.0)
+				assertEqual(t, cfg.Auth.ClientID.Value(), "profile-client")
+			},
+			sources: map[string]string{"port": "file:profile", "limit": "file:"},
+		},
+		{
+			name: "默认值",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Trace.ResponseHeader, "X-Trace-Id")
+			},
+			sources: map[string]string{"trace.responseheader": SourceDefault},
+		},
+		{
+			name:    "配置文件覆盖驼峰键名的默认值",
+			overlay: "trace:\n  responseHeader: X-Other\n",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Trace.ResponseHeader, "X-Other")
+				assertEqual(t, cfg.Trace.Header, "X-Request-Id")
+			},
+			sources: map[string]string{"trace.responseheader": "file:profile", "trace.header": SourceDefault},
+		},
+		{
+			name:    "配置文件用空值覆盖默认值",
+			overlay: "trace:\n  responseHeader: \"\"\n",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Trace.ResponseHeader, "")
+			},
+			sources: map[string]string{"trace.responseheader": "file:profile"},
+		},
+		{
+			name:    "环境配置中的数组整体替换",
+			overlay: "redis:\n  instances:\n    - name: other\n      addr: 10.0.0.1\n      port: 6380\n      dbs:\n        - db: 2\n",
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, len(cfg.Redis.Instances), 1)
+				assertEqual(t, cfg.Redis.Instances[0].Name, "other")
+				assertEqual(t, cfg.Redis.Instances[0].Password.Value(), "")
+			},
+		},
+		{
+			name:    "环境变量覆盖配置文件, 支持数组下标",
+			overlay: "port: 9090\n",
+			env: map[string]string{
+				"SERVICE_PORT":                       "9191",
+				"SERVICE_REDIS_INSTANCES_0_PASSWORD": "env-pw",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Port, 9191)
+				assertEqual(t, cfg.Redis.Instances[0].Password.Value(), "env-pw")
+			},
+			sources: map[string]string{"port": SourceEnv, "redis.instances.0.password": SourceEnv},
+		},
+		{
+			name: "环境变量匹配包含 _ 的键",
+			env:  map[string]string{"SERVICE_REDIS_INSTANCES_0_DBS_1_POOL_SIZE": "42"},
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Redis.Instances[0].DBs[1].PoolSize, 42)
+				assertEqual(t, cfg.Redis.Instances[0].DBs[0].PoolSize, 10)
+			},
+			sources: map[string]string{"redis.instances.0.dbs.1.pool_size": SourceEnv},
+		},
+		{
+			name: "配置文件和默认值都没有的层级",
+			env:  map[string]string{"SERVICE_ADMIN_TOKEN": "tok", "SERVICE_ADMIN_PORT": "9100"},
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Admin.Token.Value(), "tok")
+				assertEqual(t, cfg.Admin.Port, 9100)
+				_, fake := cfg.Sources()["admin_token"]
+				assertEqual(t, fake, false)
+			},
+			sources: map[string]string{"admin.token": SourceEnv, "admin.port": SourceEnv},
+		},
+		{
+			name:    "命令行参数优先级最高",
+			overlay: "port: 9090\n",
+			env:     map[string]string{"SERVICE_PORT": "9191"},
+			port:    9292,
+			check: func(t *testing.T, cfg *Config) {
+				assertEqual(t, cfg.Port, 9292)
+			},
+			sources: map[string]string{"port": SourceFlag},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupFiles(t, baseYAML, tt.overlay)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			port = tt.port
+
+			cfg, err := load()
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			tt.check(t, cfg)
+
+			sources := cfg.Sources()
+			for path, want := range tt.sources {
+				switch want {
+				case "file:":
+					want = "file:" + configFile
+				case "file:profile":
+					want = "file:" + profileFile(configFile, profile)
+				}
+				assertEqual(t, sources[path], want)
+			}
+		})
+	}
+}
+
+func TestSetByPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []string
+		wantPath string
+		wantOK   bool
+	}{
+		{"顶层键", []string{"port"}, "port", true},
+		{"嵌套键", []string{"zap", "level"}, "zap.level", true},
+		{"数组下标", []string{"redis", "instances", "0", "password"}, "redis.instances.0.password", true},
+		{"键名包含 _", []string{"redis", "instances", "0", "dbs", "0", "pool", "size"}, "redis.instances.0.dbs.0.pool_size", true},
+		{"缺少的层级按字段结构创建", []string{"admin", "token"}, "admin.token", true},
+		{"缺少的多层按字段结构创建", []string{"zap", "sampling", "initial"}, "zap.sampling.initial", true},
+		{"不存在的字段", []string{"zap", "new", "key"}, "", false},
+		{"不存在的顶层字段", []string{"unknown"}, "", false},
+		{"不能用字符串覆盖整个结构体", []string{"zap"}, "", false},
+		{"数组下标越界", []string{"redis", "instances", "3", "password"}, "", false},
+		{"数组下标不是数字", []string{"redis", "instances", "x"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := map[string]any{
+				"port": 8080,
+				"zap":  map[string]any{"level": "info"},
+				"redis": map[string]any{
+					"instances": []any{
+						map[string]any{"password": "", "dbs": []any{map[string]any{"pool_size": 10}}},
+					},
+				},
+			}
+			before := fmt.Sprint(settings)
+			path, ok := setByPath(settings, configType, tt.parts, "v")
+			assertEqual(t, ok, tt.wantOK)
+			assertEqual(t, path, tt.wantPath)
+			if !ok {
+				assertEqual(t, fmt.Sprint(settings), before) // 匹配失败时不修改配置
+			}
+		})
+	}
+}
+
+func TestApplyEnvIgnoresOtherVariables(t *testing.T) {
+	settings := map[string]any{"port": 8080}
+	sources := map[string]string{}
+	ignored := applyEnv(settings, sources, []string{"PATH=/bin", "SERVICE_CONFIG=/etc/a.yaml", "SERVICE_PROFILE=prod", "SERVICE_ADMIN_TOKENS=x"})
+	assertEqual(t, settings, map[string]any{"port": 8080})
+	assertEqual(t, len(sources), 0)
+	assertEqual(t, ignored, []string{"SERVICE_ADMIN_TOKENS"})
+}
+
+// TestSourcesMatchJSON 确保 Sources 的路径能在 /admin/config 返回的 JSON 中找到
+func TestSourcesMatchJSON(t *testing.T) {
+	setupFiles(t, baseYAML, "")
+	cfg, err := load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for path := range cfg.Sources() {
+		node := doc
+		for _, part := range strings.Split(path, ".") {
+			switch v := node.(type) {
+			case map[string]any:
+				node = v[part]
+			case []any:
+				i, err := strconv.Atoi(part)
+				if err != nil || i >= len(v) {
+					node = nil
+				} else {
+					node = v[i]
+				}
+			default:
+				node = nil
+			}
+			if node == nil {
+				break
+			}
+		}
+		if node == nil {
+			t.Errorf("JSON 中找不到 %s", path)
+		}
+	}
+}
+
+func assertEqual[T any](t *testing.T, got, want T) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// 默认配置的键名必须小写, 否则与配置文件中的同名键并存, 覆盖不生效
+func TestDefaultSettingsLowercase(t *testing.T) {
+	var check func(prefix string, node map[string]any)
+	check = func(prefix string, node map[string]any) {
+		for key, value := range node {
+			if key != strings.ToLower(key) {
+				t.Errorf("默认配置键名不是小写: %s 下的 %s", prefix, key)
+			}
+			if child, ok := value.(map[string]any); ok {
+				check(joinPath(prefix, key), child)
+			}
+		}
+	}
+	check("", defaultSettings())
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// configType 配置结构体类型, 环境变量按其字段结构匹配
+var configType = reflect.TypeOf(Config{})
+
+// applyEnv 使用 SERVICE_ 前缀的环境变量覆盖配置并记录来源, 返回无法匹配配置项而被忽略的变量名
+// 变量名去掉前缀后按 _ 拆分, 逐层匹配 Config 的字段(忽略大小写, 键名本身可以包含 _)和数组下标
+func applyEnv(settings map[string]any, sources map[string]string, environ []string) []string {
+	var ignored []string
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) || name == envConfigFile || name == envProfile {
+			continue
+		}
+		parts := strings.Split(strings.ToLower(strings.TrimPrefix(name, envPrefix)), "_")
+		if path, ok := setByPath(settings, configType, parts, value); ok {
+			markSource(sources, path, value, SourceEnv)
+		} else {
+			ignored = append(ignored, name)
+		}
+	}
+	return ignored
+}
+
+// setByPath 按结构体 typ 的字段写入值并返回点分路径, 键名优先匹配最长的片段
+// 配置中缺少的层级按字段结构创建, 数组只能覆盖已有的元素, 无法匹配字段时不做修改并返回 false
+func setByPath(node map[string]any, typ reflect.Type, parts []string, value string) (string, bool) {
+	for i := len(parts); i > 0; i-- {
+		key := strings.Join(parts[:i], "_")
+		field, ok := fieldByKey(typ, key)
+		if !ok {
+			continue
+		}
+		if path, ok := setField(node, key, field.Type, parts[i:], value); ok {
+			return joinPath(key, path), true
+		}
+	}
+	return "", false
+}
+
+// setField 写入 node[key], 类型为 typ, rest 为剩余的路径
+func setField(node map[string]any, key string, typ reflect.Type, rest []string, value string) (string, bool) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if len(rest) == 0 {
+		if typ.Kind() == reflect.Struct {
+			return "", false
+		}
+		node[key] = value
+		return "", true
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		child, ok := node[key].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+		}
+		path, ok := setByPath(child, typ, rest, value)
+		if ok {
+			node[key] = child
+		}
+		return path, ok
+	case reflect.Slice:
+		list, _ := node[key].([]any)
+		idx, err := strconv.Atoi(rest[0])
+		if err != nil || idx < 0 || idx >= len(list) {
+			return "", false
+		}
+		elem := typ.Elem()
+		if len(rest) == 1 {
+			if elem.Kind() == reflect.Struct {
+				return "", false
+			}
+			list[idx] = value
+			return rest[0], true
+		}
+		child, ok := list[idx].(map[string]any)
+		if !ok || elem.Kind() != reflect.Struct {
+			return "", false
+		}
+		if path, ok := setByPath(child, elem, rest[1:], value); ok {
+			return joinPath(rest[0], path), true
+		}
+	}
+	return "", false
+}
+
+// fieldByKey 按配置键查找字段, 配置键即 json 标签, 与 viper 的小写键一致
+func fieldByKey(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
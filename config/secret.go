@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Secret 敏感配置, 打印、记录日志和序列化时自动脱敏, 明文通过 Value 获取
+//
+// 配置值支持引用写法 <scheme>:<ref>, 加载时交给对应的 SecretResolver 解析, 内置:
+//   - file:/run/secrets/redis_pw 读取文件内容
+//   - env:REDIS_PW 读取环境变量
+//
+// 未注册的 scheme 按明文处理.
+type Secret string
+
+const redacted = "******"
+
+// Value 获取明文
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String 脱敏输出
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString 脱敏输出, 用于 %#v
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// MarshalJSON 脱敏序列化
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SecretResolver 密钥解析器, 根据引用获取明文
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretResolverFunc 函数形式的密钥解析器
+type SecretResolverFunc func(ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"file": SecretResolverFunc(resolveFile),
+		"env":  SecretResolverFunc(resolveEnv),
+	}
+)
+
+// RegisterSecretResolver 注册密钥解析器, 同名 scheme 会被覆盖, 需在 InitConfig 之前注册
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+// resolve 解析引用并替换为明文
+func (s *Secret) resolve() error {
+	scheme, ref, ok := strings.Cut(string(*s), ":")
+	if !ok {
+		return nil
+	}
+	resolversMu.RLock()
+	resolver, exists := resolvers[scheme]
+	resolversMu.RUnlock()
+	if !exists {
+		return nil
+	}
+	value, err := resolver.Resolve(ref)
+	if err != nil {
+		return fmt.Errorf("解析密钥 %s 失败: %w", scheme, err)
+	}
+	*s = Secret(value)
+	return nil
+}
+
+// resolveSecrets 解析配置中的全部密钥引用
+func (c *Config) resolveSecrets() error {
+	var errs []error
+	resolve := func(field string, s *Secret) {
+		if err := s.resolve(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	resolve("auth.clientId", &c.Auth.ClientID)
+	resolve("admin.token", &c.Admin.Token)
+	for i := range c.Redis.Instances {
+		resolve(fmt.Sprintf("redis.instances[%d].password", i), &c.Redis.Instances[i].Password)
+		resolve(fmt.Sprintf("redis.instances[%d].sentinelPassword", i), &c.Redis.Instances[i].SentinelPassword)
+	}
+	return errors.Join(errs...)
+}
+
+func resolveFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	return value, nil
+}
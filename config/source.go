@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// 配置值来源, 配置文件的来源为 file:<路径>
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// defaultSettings 默认配置, 每次返回新的 map 以免合并时被修改
+// 键名必须小写, 与 viper 读取配置文件后的键一致, 否则合并时不会覆盖而是并存
+func defaultSettings() map[string]any {
+	return map[string]any{
+		"debug": "release",
+		"shutdown": map[string]any{
+			"timeout": 30,
+			"delay":   5, // 就绪检查返回不可用后等待负载均衡撤走流量, 为0时立即停止接收新连接
+		},
+		"trace": map[string]any{
+			"header":         "X-Request-Id",
+			"responseheader": "X-Trace-Id",
+		},
+		"zap": map[string]any{
+			"director": "log",
+			"level":    "info",
+			"format":   "console",
+			"redact": map[string]any{
+				"headers": []any{"clientId", "Authorization", "Cookie", "Set-Cookie"},
+			},
+		},
+	}
+}
+
+// Sources 返回每个配置项的来源, 键为小写的点分路径, 如 redis.instances.0.password
+func (c *Config) Sources() map[string]string {
+	return maps.Clone(c.sources)
+}
+
+// mergeSettings 将 src 合并进 dst 并记录来源, map 逐层合并, 其余类型(包括数组)整体替换
+func mergeSettings(dst, src map[string]any, sources map[string]string, prefix, source string) {
+	for key, value := range src {
+		path := joinPath(prefix, key)
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeSettings(dstMap, srcMap, sources, path, source)
+			continue
+		}
+		dst[key] = value
+		markSource(sources, path, value, source)
+	}
+}
+
+// markSource 记录 value 下所有叶子节点的来源, 同时清除被替换掉的旧记录
+func markSource(sources map[string]string, path string, value any, source string) {
+	for key := range sources {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(sources, key)
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			markSource(sources, joinPath(path, key), child, source)
+		}
+	case []any:
+		if len(v) == 0 {
+			sources[path] = source
+		}
+		for i, child := range v {
+			markSource(sources, joinPath(path, strconv.Itoa(i)), child, source)
+		}
+	default:
+		sources[path] = source
+	}
+}
+
+// joinPath 拼接来源路径, 与 viper 一样统一小写, 和 /admin/config 返回的 json 键一致
+func joinPath(prefix, key string) string {
+	key = strings.ToLower(key)
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return fmt.Sprintf("%s.%s", prefix, key)
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+var (
+	debugModes = []string{"debug", "release", "test"}
+	zapLevels  = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+	zapFormats = []string{"console", "json"}
+	categories = []string{"app", "access", "error"}
+	sinkTypes  = []string{"file", "stdout", "stderr", "syslog", "tcp", "udp"}
+	overflows  = []string{"block", "drop"}
+	redisModes = []string{"standalone", "sentinel", "cluster"}
+	redisInits = []string{"eager", "lazy", "optional"}
+)
+
+// Validate 校验配置, 一次性返回全部问题
+func (c *Config) Validate() error {
+	var errs []error
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains(debugModes, c.Debug) {
+		addErr("debug 必须是 %v 之一, 当前为 %q", debugModes, c.Debug)
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		addErr("port 必须在 1-65535 之间, 当前为 %d", c.Port)
+	}
+	if c.Limit <= 0 {
+		addErr("limit 必须大于 0, 当前为 %v", c.Limit)
+	}
+	for i, origin := range c.AllowOrigins {
+		if origin == "" {
+			addErr("allowOrigins[%d] 不能为空", i)
+		}
+	}
+
+	// 日志
+	if c.Zap.Director == "" {
+		addErr("zap.director 不能为空")
+	}
+	if !slices.Contains(zapLevels, c.Zap.Level) {
+		addErr("zap.level 必须是 %v 之一, 当前为 %q", zapLevels, c.Zap.Level)
+	}
+	// 未配置时按 console 输出
+	if c.Zap.Format != "" && !slices.Contains(zapFormats, c.Zap.Format) {
+		addErr("zap.format 必须是 %v 之一, 当前为 %q", zapFormats, c.Zap.Format)
+	}
+	if c.Zap.MaxAge < 0 || c.Zap.MaxSize < 0 || c.Zap.MaxBackups < 0 {
+		addErr("zap.maxAge、zap.maxSize、zap.maxBackups 不能为负数")
+	}
+	if c.Zap.RevertMinutes < 0 {
+		addErr("zap.revertMinutes 不能为负数, 当前为 %d", c.Zap.RevertMinutes)
+	}
+
+	if c.Zap.Sampling.Initial < 0 || c.Zap.Sampling.Thereafter < 0 || c.Zap.Sampling.ReportInterval < 0 {
+		addErr("zap.sampling.initial、thereafter、reportInterval 不能为负数")
+	}
+	if c.Zap.Async.BufferSize < 0 || c.Zap.Async.FlushInterval < 0 {
+		addErr("zap.async.bufferSize、flushInterval 不能为负数")
+	}
+	if c.Zap.Async.Overflow != "" && !slices.Contains(overflows, c.Zap.Async.Overflow) {
+		addErr("zap.async.overflow 必须是 %v 之一, 当前为 %q", overflows, c.Zap.Async.Overflow)
+	}
+	for i, pattern := range c.Zap.Redact.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			addErr("zap.redact.patterns[%d] 正则错误: %v", i, err)
+		}
+	}
+	filenames := make(map[string]struct{}, len(c.Zap.Sinks))
+	for i, sink := range c.Zap.Sinks {
+		field := fmt.Sprintf("zap.sinks[%d]", i)
+		switch sink.Type {
+		case "", "file":
+			if sink.Filename == "" {
+				addErr("%s.filename 不能为空", field)
+			} else if _, ok := filenames[sink.Filename]; ok {
+				addErr("%s.filename 重复: %s", field, sink.Filename)
+			}
+			filenames[sink.Filename] = struct{}{}
+		case "tcp", "udp":
+			if sink.Address == "" {
+				addErr("%s.address 不能为空", field)
+			}
+		case "syslog":
+			if !slices.Contains([]string{"", "tcp", "udp"}, sink.Network) {
+				addErr("%s.network 必须是 tcp、udp 或为空, 当前为 %q", field, sink.Network)
+			}
+		case "stdout", "stderr":
+		default:
+			addErr("%s.type 必须是 %v 之一, 当前为 %q", field, sinkTypes, sink.Type)
+		}
+		if sink.Format != "" && !slices.Contains(zapFormats, sink.Format) {
+			addErr("%s.format 必须是 %v 之一, 当前为 %q", field, zapFormats, sink.Format)
+		}
+		if sink.BufferSize < 0 {
+			addErr("%s.bufferSize 不能为负数", field)
+		}
+		for _, category := range sink.Categories {
+			if !slices.Contains(categories, category) {
+				addErr("%s.categories 必须是 %v 之一, 当前为 %q", field, categories, category)
+			}
+		}
+		if sink.Level != "" && !slices.Contains(zapLevels, sink.Level) {
+			addErr("%s.level 必须是 %v 之一, 当前为 %q", field, zapLevels, sink.Level)
+		}
+		if sink.MaxAge < 0 || sink.MaxSize < 0 || sink.MaxBackups < 0 {
+			addErr("%s.maxAge、maxSize、maxBackups 不能为负数", field)
+		}
+	}
+
+	// 链路追踪
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			if c.Tracing.Endpoint == "" {
+				addErr("tracing.endpoint 不能为空")
+			}
+		case "file":
+			if c.Tracing.File == "" {
+				addErr("tracing.file 不能为空")
+			}
+		case "":
+			addErr("tracing.exporter 不能为空")
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		addErr("tracing.sampleRatio 必须在 0-1 之间, 当前为 %v", c.Tracing.SampleRatio)
+	}
+
+	// 管理端口
+	if c.Admin.Port < 0 || c.Admin.Port > 65535 {
+		addErr("admin.port 必须在 0-65535 之间, 当前为 %d", c.Admin.Port)
+	} else if c.Admin.Port != 0 && c.Admin.Port == c.Port {
+		addErr("admin.port 不能与 port 相同: %d", c.Port)
+	}
+
+	// 优雅关闭
+	if c.Shutdown.Timeout <= 0 {
+		addErr("shutdown.timeout 必须大于 0, 当前为 %d", c.Shutdown.Timeout)
+	}
+	if c.Shutdown.Delay < 0 {
+		addErr("shutdown.delay 不能为负数, 当前为 %d", c.Shutdown.Delay)
+	}
+
+	// 鉴权
+	if c.Auth.ClientID == "" {
+		addErr("auth.clientId 不能为空")
+	}
+
+	// Redis
+	names := make(map[string]struct{}, len(c.Redis.Instances))
+	for i, instance := range c.Redis.Instances {
+		field := fmt.Sprintf("redis.instances[%d]", i)
+		if instance.Name == "" {
+			addErr("%s.name 不能为空", field)
+		} else if _, ok := names[instance.Name]; ok {
+			addErr("%s.name 重复: %s", field, instance.Name)
+		}
+		names[instance.Name] = struct{}{}
+		switch instance.Mode {
+		case "", "standalone":
+			if instance.Addr == "" {
+				addErr("%s.addr 不能为空", field)
+			}
+			if instance.Port <= 0 || instance.Port > 65535 {
+				addErr("%s.port 必须在 1-65535 之间, 当前为 %d", field, instance.Port)
+			}
+		case "sentinel":
+			if instance.MasterName == "" {
+				addErr("%s.masterName 不能为空", field)
+			}
+			if len(instance.SentinelAddrs) == 0 {
+				addErr("%s.sentinelAddrs 不能为空", field)
+			}
+		case "cluster":
+			if len(instance.ClusterAddrs) == 0 {
+				addErr("%s.clusterAddrs 不能为空", field)
+			}
+			for j, db := range instance.DBs {
+				if db.DB != 0 {
+					addErr("%s.dbs[%d].db cluster 模式只支持 db 0, 当前为 %d", field, j, db.DB)
+				}
+			}
+		default:
+			addErr("%s.mode 必须是 %v 之一, 当前为 %q", field, redisModes, instance.Mode)
+		}
+		if instance.Init != "" && !slices.Contains(redisInits, instance.Init) {
+			addErr("%s.init 必须是 %v 之一, 当前为 %q", field, redisInits, instance.Init)
+		}
+		if instance.TLS.Enabled && (instance.TLS.CertFile == "") != (instance.TLS.KeyFile == "") {
+			addErr("%s.tls.certFile 和 keyFile 需同时配置", field)
+		}
+		breaker := instance.Breaker
+		if breaker.Failures < 0 || breaker.SlowThreshold < 0 || breaker.OpenTimeout < 0 || breaker.Probes < 0 {
+			addErr("%s.breaker.failures、slowThreshold、openTimeout、probes 不能为负数", field)
+		}
+		if len(instance.DBs) == 0 {
+			addErr("%s.dbs 不能为空", field)
+		}
+		dbs := make(map[int]struct{}, len(instance.DBs))
+		for j, db := range instance.DBs {
+			if db.DB < 0 {
+				addErr("%s.dbs[%d].db 不能为负数, 当前为 %d", field, j, db.DB)
+			}
+			if _, ok := dbs[db.DB]; ok {
+				addErr("%s.dbs[%d].db 重复: %s_%d", field, j, instance.Name, db.DB)
+			}
+			dbs[db.DB] = struct{}{}
+			if db.PoolSize < 0 {
+				addErr("%s.dbs[%d].pool_size 不能为负数, 当前为 %d", field, j, db.PoolSize)
+			}
+			if db.MinIdleConns < 0 || db.MaxIdleConns < 0 || db.DialTimeout < 0 {
+				addErr("%s.dbs[%d].min_idle_conns、max_idle_conns、dial_timeout 不能为负数", field, j)
+			}
+			if db.PoolSize > 0 && db.MinIdleConns > db.PoolSize {
+				addErr("%s.dbs[%d].min_idle_conns 不能大于 pool_size", field, j)
+			}
+			if db.ConnMaxIdleTime < -1 || db.ReadTimeout < -1 || db.WriteTimeout < -1 || db.MaxRetries < -1 {
+				addErr("%s.dbs[%d].conn_max_idle_time、read_timeout、write_timeout、max_retries 不能小于 -1", field, j)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
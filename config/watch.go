@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 编辑器保存文件时可能连续触发多个事件, 合并后再加载
+const reloadDelay = 200 * time.Millisecond
+
+var (
+	mu          sync.Mutex
+	reloadMu    sync.Mutex // 保证热加载串行执行
+	subscribers []func(*Config)
+	watcher     *fsnotify.Watcher
+)
+
+// OnChange 注册配置变更订阅者, 新配置生效后按注册顺序回调
+func OnChange(fn func(*Config)) {
+	mu.Lock()
+	defer mu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Watch 监听配置文件变更并热加载, 新配置加载失败时保留旧配置并交给 onError 处理
+func Watch(onError func(error)) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if watcher != nil {
+		return nil
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建配置监听失败: %w", err)
+	}
+	// 监听目录而不是文件, 兼容编辑器先删除再重建文件的保存方式
+	targets := make(map[string]struct{})
+	for _, file := range configFiles() {
+		targets[filepath.Clean(file)] = struct{}{}
+		if err := w.Add(filepath.Dir(file)); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("监听配置目录失败: %w", err)
+		}
+	}
+	watcher = w
+
+	go watch(w, targets, onError)
+	return nil
+}
+
+// StopWatch 停止监听配置文件
+func StopWatch() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if watcher == nil {
+		return nil
+	}
+	err := watcher.Close()
+	watcher = nil
+	return err
+}
+
+func watch(w *fsnotify.Watcher, targets map[string]struct{}, onError func(error)) {
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if _, ok := targets[filepath.Clean(event.Name)]; !ok || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDelay, func() {
+				if err := reload(); err != nil {
+					onError(err)
+				}
+			})
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			onError(fmt.Errorf("配置监听异常: %w", err))
+		}
+	}
+}
+
+// reload 重新加载配置, 成功后整体替换并通知订阅者
+func reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, err := load()
+	if err != nil {
+		return fmt.Errorf("配置热加载失败, 继续使用旧配置: %w", err)
+	}
+	appConfig.Store(cfg)
+
+	mu.Lock()
+	fns := make([]func(*Config), len(subscribers))
+	copy(fns, subscribers)
+	mu.Unlock()
+	for _, fn := range fns {
+		fn(cfg)
+	}
+	return nil
+}
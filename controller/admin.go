@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"service/config"
+	"service/logger"
+	"service/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AdminController struct {
+	Controller
+}
+
+func NewAdminController() *AdminController {
+	return &AdminController{}
+}
+
+// Config 查看当前生效的配置及每项配置的来源, 密钥已脱敏
+func (c *AdminController) Config(ctx *gin.Context) {
+	cfg := config.Get()
+	c.Success(ctx, gin.H{
+		"config":  cfg,
+		"sources": cfg.Sources(),
+	})
+}
+
+// GetLogLevel 查看日志级别
+func (c *AdminController) GetLogLevel(ctx *gin.Context) {
+	c.Success(ctx, logger.GetLevelStatus())
+}
+
+// SetLogLevel 临时调整日志级别
+func (c *AdminController) SetLogLevel(ctx *gin.Context) {
+	var req model.LogLevel
+	if err := c.Valid(ctx, &req); err != nil {
+		return
+	}
+	if err := logger.OverrideLevel(req.Level, time.Duration(req.Minutes)*time.Minute); err != nil {
+		c.Error(ctx, err.Error(), nil)
+		return
+	}
+	logger.Warn(ctx, "日志级别已调整", zap.String("level", req.Level), zap.Int("minutes", req.Minutes))
+	c.Success(ctx, logger.GetLevelStatus())
+}
+
+// ResetLogLevel 恢复为配置的日志级别
+func (c *AdminController) ResetLogLevel(ctx *gin.Context) {
+	logger.ResetLevel()
+	c.Success(ctx, logger.GetLevelStatus())
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+	"runtime/debug"
+	"runtime/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugController 运行时信息, 仅挂载在管理端口
+type DebugController struct {
+	Controller
+	public *gin.Engine // 业务端口的路由
+}
+
+func NewDebugController(public *gin.Engine) *DebugController {
+	return &DebugController{public: public}
+}
+
+// Goroutines 输出全部协程的调用栈
+func (c *DebugController) Goroutines(ctx *gin.Context) {
+	ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	_ = pprof.Lookup("goroutine").WriteTo(ctx.Writer, 2)
+}
+
+// Routes 业务端口的路由表
+func (c *DebugController) Routes(ctx *gin.Context) {
+	routes := c.public.Routes()
+	result := make([]gin.H, 0, len(routes))
+	for _, route := range routes {
+		result = append(result, gin.H{
+			"method":  route.Method,
+			"path":    route.Path,
+			"handler": route.Handler,
+		})
+	}
+	c.Success(ctx, result)
+}
+
+// BuildInfo 编译信息, 包括 Go 版本、依赖和 vcs 信息
+func (c *DebugController) BuildInfo(ctx *gin.Context) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		c.Error(ctx, "无法读取编译信息", nil)
+		return
+	}
+	settings := make(map[string]string, len(info.Settings))
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	deps := make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, dep.Path+"@"+dep.Version)
+	}
+	c.Success(ctx, gin.H{
+		"goVersion": info.GoVersion,
+		"path":      info.Path,
+		"version":   info.Main.Version,
+		"settings":  settings,
+		"deps":      deps,
+	})
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"net/http"
+	"service/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthController 存活与就绪检查, 供编排系统探测, 按 HTTP 状态码判断结果
+type HealthController struct {
+	Controller
+}
+
+func NewHealthController() *HealthController {
+	return &HealthController{}
+}
+
+// Healthz 存活检查, 进程能响应即视为存活
+func (c *HealthController) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪检查, 依赖不可用或服务正在关闭时返回 503, 不返回失败原因
+func (c *HealthController) Readyz(ctx *gin.Context) {
+	c.ready(ctx, false)
+}
+
+// ReadyzDetail 就绪检查, 附带各依赖的失败原因, 仅在管理端口提供
+func (c *HealthController) ReadyzDetail(ctx *gin.Context) {
+	c.ready(ctx, true)
+}
+
+func (c *HealthController) ready(ctx *gin.Context, detail bool) {
+	ready, report := health.Ready(ctx)
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	if !detail {
+		report = report.WithoutErrors()
+	}
+	ctx.JSON(status, report)
+}
@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"service/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VersionController struct {
+	Controller
+}
+
+func NewVersionController() *VersionController {
+	return &VersionController{}
+}
+
+// Version 编译信息
+func (c *VersionController) Version(ctx *gin.Context) {
+	c.Success(ctx, version.Get())
+}
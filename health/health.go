@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 单项检查的超时时间
+const checkTimeout = time.Second
+
+// Checker 依赖检查, 返回 nil 表示可用
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的依赖检查
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// StateReporter 可选接口, 检查项实现后在结果中附带依赖自身的状态, 如 Redis 客户端的连接状态
+type StateReporter interface {
+	State() string
+}
+
+// degradedError 降级错误, 检查结果为 degraded, 不影响就绪
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// Degraded 标记依赖降级, 用于可选依赖, 服务在其不可用时仍可对外提供服务
+func Degraded(err error) error {
+	return degradedError{err: err}
+}
+
+// Result 单项检查结果
+type Result struct {
+	Status string  `json:"status"`          // up、down、degraded
+	State  string  `json:"state,omitempty"` // 依赖自身的状态
+	Error  string  `json:"error,omitempty"` // 失败原因
+	Cost   float64 `json:"cost"`            // 耗时(秒)
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Status string            `json:"status"` // ok、degraded、unavailable、shutting_down
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+var (
+	mu           sync.RWMutex
+	checkers     = make(map[string]Checker)
+	shuttingDown atomic.Bool
+)
+
+// Register 注册依赖检查, 同名覆盖
+func Register(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = checker
+}
+
+// Unregister 移除依赖检查
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(checkers, name)
+}
+
+// SetShuttingDown 标记服务开始关闭, 之后就绪检查直接返回不可用
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// Ready 并发执行全部依赖检查, 任一失败或服务正在关闭时返回 false
+func Ready(ctx context.Context) (bool, Report) {
+	if shuttingDown.Load() {
+		return false, Report{Status: "shutting_down"}
+	}
+
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]Checker, len(names))
+	for i, name := range names {
+		list[i] = checkers[name]
+	}
+	mu.RUnlock()
+
+	results := make([]Result, len(list))
+	var wg sync.WaitGroup
+	for i, checker := range list {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = check(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	ready := true
+	report := Report{Status: "ok", Checks: make(map[string]Result, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		switch results[i].Status {
+		case "down":
+			ready = false
+		case "degraded":
+			report.Status = "degraded"
+		}
+	}
+	if !ready {
+		report.Status = "unavailable"
+	}
+	return ready, report
+}
+
+// WithoutErrors 去掉失败原因, 用于公开的就绪检查, 避免暴露依赖地址等内部信息
+func (r Report) WithoutErrors() Report {
+	if r.Checks == nil {
+		return r
+	}
+	checks := make(map[string]Result, len(r.Checks))
+	for name, result := range r.Checks {
+		result.Error = ""
+		checks[name] = result
+	}
+	r.Checks = checks
+	return r
+}
+
+func check(ctx context.Context, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := Result{Status: "up", Cost: time.Since(start).Seconds()}
+	if reporter, ok := checker.(StateReporter); ok {
+		result.State = reporter.State()
+	}
+	var degraded degradedError
+	if errors.As(err, &degraded) {
+		result.Status = "degraded"
+		result.Error = err.Error()
+	} else if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+	return result
+}
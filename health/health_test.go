@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReportWithoutErrors(t *testing.T) {
+	Register("test:down", CheckerFunc(func(context.Context) error { return errors.New("dial tcp 10.0.0.1:6379: refused") }))
+	Register("test:degraded", CheckerFunc(func(context.Context) error { return Degraded(errors.New("lazy")) }))
+	t.Cleanup(func() {
+		Unregister("test:down")
+		Unregister("test:degraded")
+	})
+
+	ready, report := Ready(context.Background())
+	if ready {
+		t.Fatal("依赖不可用时不应就绪")
+	}
+	if report.Checks["test:down"].Error == "" {
+		t.Fatal("完整报告应包含失败原因")
+	}
+
+	public := report.WithoutErrors()
+	for name, result := range public.Checks {
+		if result.Error != "" {
+			t.Errorf("%s: 公开报告不应包含失败原因, got %q", name, result.Error)
+		}
+	}
+	if public.Checks["test:down"].Status != "down" || public.Checks["test:degraded"].Status != "degraded" {
+		t.Errorf("公开报告应保留状态, got %+v", public.Checks)
+	}
+	if report.Checks["test:down"].Error == "" {
+		t.Error("不应修改原报告")
+	}
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bufio"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncBufferSize    = 4096
+	defaultAsyncFlushInterval = time.Second
+	asyncWriteBufferSize      = 256 * 1024
+)
+
+// 缓冲写满时的处理方式
+const (
+	OverflowBlock = "block" // 阻塞等待
+	OverflowDrop  = "drop"  // 丢弃并计数
+)
+
+// asyncWriter 异步写入器, 日志先进入缓冲队列, 由后台协程批量写入并定时刷新
+type asyncWriter struct {
+	out      zapcore.WriteSyncer
+	queue    chan []byte
+	block    bool
+	interval time.Duration
+	flush    chan chan struct{}
+	done     chan struct{}
+	exited   chan struct{}
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, bufferSize int, flushInterval time.Duration, overflow string) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultAsyncFlushInterval
+	}
+	w := &asyncWriter{
+		out:      out,
+		queue:    make(chan []byte, bufferSize),
+		block:    overflow != OverflowDrop,
+		interval: flushInterval,
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+		exited:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 写入缓冲队列, zap 会复用 p, 需要复制; 关闭后直接写入
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.exited:
+		return w.out.Write(p)
+	default:
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+	if w.block {
+		select {
+		case w.queue <- b:
+		case <-w.exited:
+			return w.out.Write(p)
+		}
+		return len(p), nil
+	}
+	select {
+	case w.queue <- b:
+	default:
+		bufferDropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 等待缓冲队列全部写入并刷新到底层
+func (w *asyncWriter) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.flush <- ack:
+		<-ack
+	case <-w.exited:
+	}
+	return w.out.Sync()
+}
+
+// Close 写入剩余日志后停止后台协程
+func (w *asyncWriter) Close() error {
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+	<-w.exited
+	return w.out.Sync()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.exited)
+	buf := bufio.NewWriterSize(w.out, asyncWriteBufferSize)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case b := <-w.queue:
+			_, _ = buf.Write(b)
+		case <-ticker.C:
+			_ = buf.Flush()
+		case ack := <-w.flush:
+			w.drain(buf)
+			_ = buf.Flush()
+			close(ack)
+		case <-w.done:
+			w.drain(buf)
+			_ = buf.Flush()
+			return
+		}
+	}
+}
+
+// drain 写入队列中剩余的日志
+func (w *asyncWriter) drain(buf *bufio.Writer) {
+	for {
+		select {
+		case b := <-w.queue:
+			_, _ = buf.Write(b)
+		default:
+			return
+		}
+	}
+}
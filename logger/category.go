@@ -0,0 +1,29 @@
+package logger
+
+import "context"
+
+// Category 日志分类, 不同分类可以写入不同的日志输出
+type Category string
+
+const (
+	CategoryApp    Category = "app"    // 业务日志
+	CategoryAccess Category = "access" // 访问日志
+	CategoryError  Category = "error"  // 异常日志
+)
+
+var categories = []Category{CategoryApp, CategoryAccess, CategoryError}
+
+type categoryKey struct{}
+
+// WithCategory 指定日志分类, 使用返回的 ctx 调用 Info/Warn/Error 时写入该分类的日志输出
+func WithCategory(ctx context.Context, category Category) context.Context {
+	return context.WithValue(ctx, categoryKey{}, category)
+}
+
+// categoryFrom 获取日志分类, 未指定时为业务日志
+func categoryFrom(ctx context.Context) Category {
+	if category, ok := ctx.Value(categoryKey{}).(Category); ok {
+		return category
+	}
+	return CategoryApp
+}
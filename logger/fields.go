@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+	"service/reqctx"
+
+	"go.uber.org/zap"
+)
+
+// ctxLoggerKey 上下文中缓存子日志器的键
+type ctxLoggerKey struct{}
+
+// ctxLogger 绑定了上下文字段的子日志器, 每个分类一个
+type ctxLogger struct {
+	fields  []zap.Field
+	loggers map[Category]*zap.Logger
+}
+
+// WithFields 将字段绑定到上下文, 之后使用该上下文调用 Info/Warn/Error 时自动带上这些字段
+// 子日志器在绑定时创建并缓存在上下文中, 记录日志时不再重复创建
+// 传入 *gin.Context 时写入其请求上下文并返回原上下文
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	all := fields
+	if parent, ok := reqctx.Value(ctx, ctxLoggerKey{}).(*ctxLogger); ok {
+		all = append(append(make([]zap.Field, 0, len(parent.fields)+len(fields)), parent.fields...), fields...)
+	}
+	l := &ctxLogger{
+		fields:  all,
+		loggers: make(map[Category]*zap.Logger, len(loggers)),
+	}
+	for category, log := range loggers {
+		l.loggers[category] = log.With(all...).WithOptions(zap.AddCallerSkip(2))
+	}
+	return reqctx.WithValue(ctx, ctxLoggerKey{}, l)
+}
+
+// loggerFrom 获取上下文中缓存的子日志器
+func loggerFrom(ctx context.Context, category Category) (*zap.Logger, bool) {
+	l, ok := reqctx.Value(ctx, ctxLoggerKey{}).(*ctxLogger)
+	if !ok {
+		return nil, false
+	}
+	return l.loggers[category], true
+}
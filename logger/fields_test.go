@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"service/config"
+	"service/reqctx"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// useDiscardLoggers 将各分类日志器替换为丢弃输出的 JSON 日志器, 测试结束后恢复
+func useDiscardLoggers(tb testing.TB) {
+	tb.Helper()
+	r, err := newRedactor(config.Redact{Fields: []string{"password"}})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	old := loggers
+	tb.Cleanup(func() { loggers = old })
+	core := newRedactTee([]zapcore.Core{zapcore.NewCore(getEncoder("json"), zapcore.AddSync(io.Discard), zapcore.DebugLevel)}, r)
+	loggers = make(map[Category]*zap.Logger, len(categories))
+	for _, category := range categories {
+		loggers[category] = zap.New(core, zap.AddCaller())
+	}
+}
+
+// BenchmarkLogWithFields 上下文中缓存了绑定 trace_id 的子日志器
+func BenchmarkLogWithFields(b *testing.B) {
+	useDiscardLoggers(b)
+	ctx := reqctx.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = WithFields(ctx, zap.String("trace_id", reqctx.TraceID(ctx)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Info(ctx, "请求处理完成", zap.Int("status", 200))
+	}
+}
+
+// BenchmarkLogPerCallWith 每次记录日志时临时 With(trace_id)
+func BenchmarkLogPerCallWith(b *testing.B) {
+	useDiscardLoggers(b)
+	ctx := reqctx.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Info(ctx, "请求处理完成", zap.Int("status", 200))
+	}
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	level       = zap.NewAtomicLevel() // 当前日志级别, 支持运行时调整
+	levelMu     sync.Mutex
+	configLevel = zapcore.InfoLevel // 配置文件中的日志级别
+	revertTimer *time.Timer
+	revertAt    time.Time
+	levelGen    uint64 // 每次调整级别时递增, 过期的自动恢复据此失效
+)
+
+// LevelStatus 日志级别状态
+type LevelStatus struct {
+	Level       string     `json:"level"`              // 当前级别
+	ConfigLevel string     `json:"configLevel"`        // 配置级别
+	RevertAt    *time.Time `json:"revertAt,omitempty"` // 自动恢复时间
+}
+
+// SetLevel 设置配置级别, 配置加载和热加载时调用; 级别有变化时立即生效并取消进行中的临时调整,
+// 没有变化时保留临时调整, 避免修改其他配置项时把排查用的 debug 级别重置掉
+func SetLevel(l string) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	lvl := getLevel(l)
+	if lvl == configLevel {
+		return
+	}
+	configLevel = lvl
+	setLevel(configLevel)
+}
+
+// OverrideLevel 临时调整日志级别, revertAfter 大于 0 时到期自动恢复为配置级别
+func OverrideLevel(l string, revertAfter time.Duration) error {
+	lvl, err := zapcore.ParseLevel(l)
+	if err != nil {
+		return err
+	}
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	gen := setLevel(lvl)
+	if revertAfter > 0 {
+		revertAt = time.Now().Add(revertAfter)
+		revertTimer = time.AfterFunc(revertAfter, func() { revertLevel(gen) })
+	}
+	return nil
+}
+
+// ResetLevel 恢复为配置级别
+func ResetLevel() {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	setLevel(configLevel)
+}
+
+// revertLevel 到期自动恢复, 期间级别又被调整过(gen 已过期)时不处理;
+// Stop 无法拦截已经触发、正在等锁的回调, 需要靠 gen 判断
+func revertLevel(gen uint64) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if gen != levelGen {
+		return
+	}
+	setLevel(configLevel)
+}
+
+// GetLevelStatus 获取当前日志级别状态
+func GetLevelStatus() LevelStatus {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	status := LevelStatus{
+		Level:       level.Level().String(),
+		ConfigLevel: configLevel.String(),
+	}
+	if revertTimer != nil {
+		at := revertAt
+		status.RevertAt = &at
+	}
+	return status
+}
+
+// setLevel 取消进行中的自动恢复并设置级别, 返回新的 gen, 调用方需持有 levelMu
+func setLevel(lvl zapcore.Level) uint64 {
+	stopRevert()
+	level.SetLevel(lvl)
+	levelGen++
+	return levelGen
+}
+
+func stopRevert() {
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+	}
+}
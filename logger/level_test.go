@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// resetLevelState 恢复包级别状态, 避免测试之间互相影响
+func resetLevelState(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		levelMu.Lock()
+		defer levelMu.Unlock()
+		configLevel = zapcore.InfoLevel
+		setLevel(configLevel)
+	})
+}
+
+func TestSetLevelKeepsOverrideWhenUnchanged(t *testing.T) {
+	resetLevelState(t)
+	SetLevel("info")
+	if err := OverrideLevel("debug", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	SetLevel("info") // 热加载但级别未变
+	if got := level.Level(); got != zapcore.DebugLevel {
+		t.Fatalf("级别未变时不应重置临时调整, got %s", got)
+	}
+	if GetLevelStatus().RevertAt == nil {
+		t.Fatal("自动恢复不应被取消")
+	}
+
+	SetLevel("warn") // 级别变化
+	if got := level.Level(); got != zapcore.WarnLevel {
+		t.Fatalf("级别变化时应立即生效, got %s", got)
+	}
+	if GetLevelStatus().RevertAt != nil {
+		t.Fatal("级别变化时应取消自动恢复")
+	}
+}
+
+func TestStaleRevertIsNoop(t *testing.T) {
+	resetLevelState(t)
+	SetLevel("info")
+	if err := OverrideLevel("debug", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	levelMu.Lock()
+	stale := levelGen
+	levelMu.Unlock()
+
+	// 模拟旧的定时器已经触发, 但在拿到锁之前又有新的临时调整
+	if err := OverrideLevel("error", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	revertLevel(stale)
+	if got := level.Level(); got != zapcore.ErrorLevel {
+		t.Fatalf("过期的自动恢复不应生效, got %s", got)
+	}
+}
+
+func TestOverrideLevelReverts(t *testing.T) {
+	resetLevelState(t)
+	SetLevel("info")
+	if err := OverrideLevel("debug", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for level.Level() != zapcore.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("到期后应恢复为配置级别, got %s", level.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
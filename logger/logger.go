@@ -4,9 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path"
 	"service/config"
+	"service/reqctx"
 	"service/util"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,57 +18,119 @@ import (
 )
 
 var (
-	once   sync.Once
-	logger *zap.Logger
+	once    sync.Once
+	logger  *zap.Logger              // 业务日志器
+	loggers map[Category]*zap.Logger // 各分类的日志器
 )
 
 // InitLogger 初始化全局日志器
 func InitLogger() error {
 	var err error
 	once.Do(func() {
-		logger, err = NewLogger()
+		loggers, err = NewLoggers()
 		if err == nil {
+			logger = loggers[CategoryApp]
 			zap.ReplaceGlobals(logger)
+			startDropReport(config.Get().Zap.Sampling)
 		}
 	})
 	return err
 }
 
-// NewLogger 创建新的日志器实例
-func NewLogger() (*zap.Logger, error) {
+// Close 停止日志后台任务并刷新缓冲, 服务退出前调用, 之后的日志同步写入
+// ctx 到期时不再等待, 避免采集端不可用时阻塞退出
+func Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// 先刷新缓冲, 保证丢弃条数的汇总日志不会因缓冲已满被丢弃
+		for _, log := range loggers {
+			_ = log.Sync()
+		}
+		stopDropReport()
+		for _, closer := range closers {
+			_ = closer.Close()
+		}
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("刷新日志超时: %w", ctx.Err())
+	}
+}
+
+// NewLoggers 按配置的日志输出为每个分类创建日志器
+func NewLoggers() (map[Category]*zap.Logger, error) {
+	cfg := config.Get()
+	// 未配置日志输出时全部写入 service.log
+	sinks := cfg.Zap.Sinks
+	if len(sinks) == 0 {
+		sinks = []config.Sink{{Filename: "service.log"}}
+	}
 	// 创建日志目录
-	if err := ensureLogDirectoryExists(config.AppConfig.Zap.Director); err != nil {
-		return nil, err
+	if hasFileSink(sinks) {
+		if err := ensureLogDirectoryExists(cfg.Zap.Director); err != nil {
+			return nil, err
+		}
 	}
 	// 设置日志级别
-	writer := getLogWriter(config.AppConfig.Zap.Director, config.AppConfig.Zap.MaxSize, config.AppConfig.Zap.MaxBackups, config.AppConfig.Zap.MaxAge)
-	// debug模式输出控制台
-	if config.AppConfig.Debug == "debug" {
-		writer = zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), writer)
-	}
-	// 创建编码器配置
-	encoderConfig := getEncoderConfig()
-	var core zapcore.Core
-	if config.AppConfig.Zap.Format == "json" {
-		// 如果是JSON格式则使用JSONEncoder
-		core = zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), writer, levelPriority(getLevel(config.AppConfig.Zap.Level)))
-	} else {
-		// 如果是Console格式则使用ConsoleEncoder
-		core = zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), writer, levelPriority(getLevel(config.AppConfig.Zap.Level)))
+	SetLevel(cfg.Zap.Level)
+	// 脱敏规则
+	r, err := newRedactor(cfg.Zap.Redact)
+	if err != nil {
+		return nil, err
 	}
+	redaction = r
 
-	log := zap.New(core)
-	log = log.WithOptions(zap.AddCaller())
+	cores := make(map[Category][]zapcore.Core, len(categories))
+	for _, sink := range sinks {
+		core, err := newSinkCore(cfg.Zap, sink)
+		if err != nil {
+			return nil, err
+		}
+		for _, category := range categories {
+			if len(sink.Categories) == 0 || slices.Contains(sink.Categories, string(category)) {
+				cores[category] = append(cores[category], core)
+			}
+		}
+	}
+	// debug模式输出控制台
+	if cfg.Debug == "debug" && !hasStdoutSink(sinks) {
+		console := zapcore.NewCore(getEncoder(cfg.Zap.Format), zapcore.AddSync(os.Stdout), level)
+		for _, category := range categories {
+			cores[category] = append(cores[category], console)
+		}
+	}
 
-	return log, nil
+	result := make(map[Category]*zap.Logger, len(categories))
+	for _, category := range categories {
+		// 先采样再脱敏, 被采样丢弃的日志不做脱敏
+		core := newSampler(newRedactTee(cores[category], r), cfg.Zap.Sampling)
+		result[category] = zap.New(core, zap.AddCaller())
+	}
+	return result, nil
 }
 
-// logWithTraceID 带有 TraceID 的日志记录
+// logWithTraceID 带有 TraceID 的日志记录, 按 ctx 中的分类选择日志器
+// 上下文中已通过 WithFields 缓存子日志器时直接使用, 否则临时附加 trace_id
 func logWithTraceID(ctx context.Context, level zapcore.Level, msg string, fields ...zap.Field) {
-	traceID, _ := ctx.Value("TraceID").(string)
-	if logger.Core().Enabled(level) {
-		logger.With(zap.Any("trace_id", traceID)).WithOptions(zap.AddCallerSkip(2)).Log(level, msg, fields...)
+	category := categoryFrom(ctx)
+	if log, ok := loggerFrom(ctx, category); ok {
+		if ce := log.Check(level, msg); ce != nil {
+			ce.Write(fields...)
+		}
+		return
 	}
+	log := loggers[category]
+	if log.Core().Enabled(level) {
+		log.With(zap.Any("trace_id", reqctx.TraceID(ctx))).WithOptions(zap.AddCallerSkip(2)).Log(level, msg, fields...)
+	}
+}
+
+// Debug 记录 Debug 级别日志
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	logWithTraceID(ctx, zapcore.DebugLevel, msg, fields...)
 }
 
 // Info 记录 Info 级别日志
@@ -102,10 +165,9 @@ func ensureLogDirectoryExists(director string) error {
 }
 
 // 获取日志文件写入器
-func getLogWriter(director string, maxSize, maxBackups, maxAge int) zapcore.WriteSyncer {
-	logFileName := path.Join(director, "service.log")
+func getLogWriter(filename string, maxSize, maxBackups, maxAge int) zapcore.WriteSyncer {
 	return zapcore.AddSync(&lumberjack.Logger{
-		Filename:   logFileName,
+		Filename:   filename,
 		MaxSize:    maxSize,
 		MaxBackups: maxBackups,
 		MaxAge:     maxAge,
@@ -113,6 +175,16 @@ func getLogWriter(director string, maxSize, maxBackups, maxAge int) zapcore.Writ
 	})
 }
 
+// 获取编码器
+func getEncoder(format string) zapcore.Encoder {
+	if format == "json" {
+		// 如果是JSON格式则使用JSONEncoder
+		return zapcore.NewJSONEncoder(getEncoderConfig())
+	}
+	// 如果是Console格式则使用ConsoleEncoder
+	return zapcore.NewConsoleEncoder(getEncoderConfig())
+}
+
 // 获取编码配置
 func getEncoderConfig() zapcore.EncoderConfig {
 	return zapcore.EncoderConfig{
@@ -149,9 +221,20 @@ func getLevel(level string) zapcore.Level {
 	return zapcore.DebugLevel
 }
 
-// 获取日志级别优先级
-func levelPriority(level zapcore.Level) zap.LevelEnablerFunc {
+// 获取日志输出的级别过滤, 需同时满足全局级别和输出自身的最低级别
+func sinkLevel(min string) zap.LevelEnablerFunc {
+	if min == "" {
+		return level.Enabled
+	}
+	minLevel := getLevel(min)
 	return func(l zapcore.Level) bool {
-		return l >= level
+		return level.Enabled(l) && l >= minLevel
+	}
+}
+
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
 	}
+	return value
 }
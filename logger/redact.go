@@ -0,0 +1,214 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"service/config"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const masked = "******"
+
+// redactor 日志脱敏规则
+type redactor struct {
+	headers  map[string]struct{} // 请求头, 小写
+	names    map[string]struct{} // 任意层级匹配的字段名, 小写
+	paths    map[string]struct{} // 从日志字段名开始匹配的字段路径, 小写
+	patterns []*regexp.Regexp
+}
+
+var redaction = &redactor{}
+
+// newRedactor 根据配置创建脱敏规则
+func newRedactor(cfg config.Redact) (*redactor, error) {
+	r := &redactor{
+		headers: make(map[string]struct{}, len(cfg.Headers)),
+		names:   make(map[string]struct{}),
+		paths:   make(map[string]struct{}),
+	}
+	for _, header := range cfg.Headers {
+		r.headers[strings.ToLower(header)] = struct{}{}
+	}
+	for _, field := range cfg.Fields {
+		field = strings.ToLower(field)
+		if strings.Contains(field, ".") {
+			r.paths[field] = struct{}{}
+		} else {
+			r.names[field] = struct{}{}
+		}
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("脱敏正则 %q 错误: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// DumpRequest 输出请求信息, 配置的请求头已脱敏
+func DumpRequest(req *http.Request) string {
+	clone := req.Clone(req.Context())
+	for name := range clone.Header {
+		if _, ok := redaction.headers[strings.ToLower(name)]; ok {
+			clone.Header.Set(name, masked)
+		}
+	}
+	dump, _ := httputil.DumpRequest(clone, false)
+	return string(dump)
+}
+
+// matches 判断字段是否需要脱敏
+func (r *redactor) matches(name, path string) bool {
+	if _, ok := r.names[strings.ToLower(name)]; ok {
+		return true
+	}
+	_, ok := r.paths[strings.ToLower(path)]
+	return ok
+}
+
+// redactString 按正则脱敏字符串
+func (r *redactor) redactString(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, masked)
+	}
+	return s
+}
+
+// redactFields 脱敏日志字段, 复杂类型先转为 map、slice 结构再逐层处理
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		result = append(result, r.redactField(field))
+	}
+	return result
+}
+
+func (r *redactor) redactField(field zapcore.Field) zapcore.Field {
+	if r.matches(field.Key, field.Key) {
+		return zap.String(field.Key, masked)
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.redactString(field.String)
+	case zapcore.ErrorType, zapcore.StringerType:
+		text := fmt.Sprint(field.Interface)
+		if redacted := r.redactString(text); redacted != text {
+			return zap.String(field.Key, redacted)
+		}
+	case zapcore.ReflectType:
+		data, err := json.Marshal(field.Interface)
+		if err != nil {
+			return field
+		}
+		// 数字保持原文, 避免超过 2^53 的整数ID转为 float64 后失真
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return field
+		}
+		return zap.Any(field.Key, r.redactValue(value, field.Key))
+	case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+		// zap.Strings、zap.Any([]string)、zap.Object、zap.Array 等, 经 MapObjectEncoder 展开
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		value, ok := enc.Fields[field.Key]
+		if !ok {
+			return field
+		}
+		return zap.Any(field.Key, r.redactValue(value, field.Key))
+	}
+	return field
+}
+
+func (r *redactor) redactValue(value any, path string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := path + "." + key
+			if r.matches(key, childPath) {
+				v[key] = masked
+			} else {
+				v[key] = r.redactValue(child, childPath)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = r.redactValue(child, path)
+		}
+	case string:
+		return r.redactString(v)
+	}
+	return value
+}
+
+// redactTee 在写入各个日志输出之前统一脱敏一次, 替代 zapcore.NewTee
+// 写入同一分类下多个输出的日志只脱敏一次, 复杂字段只做一次 JSON 转换
+type redactTee struct {
+	cores []zapcore.Core
+	r     *redactor
+}
+
+func newRedactTee(cores []zapcore.Core, r *redactor) zapcore.Core {
+	return &redactTee{cores: cores, r: r}
+}
+
+func (t *redactTee) Enabled(lvl zapcore.Level) bool {
+	for _, core := range t.cores {
+		if core.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *redactTee) With(fields []zapcore.Field) zapcore.Core {
+	fields = t.r.redactFields(fields)
+	cores := make([]zapcore.Core, len(t.cores))
+	for i, core := range t.cores {
+		cores[i] = core.With(fields)
+	}
+	return &redactTee{cores: cores, r: t.r}
+}
+
+func (t *redactTee) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if t.Enabled(ent.Level) {
+		return ce.AddCore(ent, t)
+	}
+	return ce
+}
+
+// Write 脱敏后写入级别满足的输出, 各输出的最低级别可能不同
+func (t *redactTee) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = t.r.redactString(ent.Message)
+	fields = t.r.redactFields(fields)
+	var errs []error
+	for _, core := range t.cores {
+		if core.Enabled(ent.Level) {
+			if err := core.Write(ent, fields); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *redactTee) Sync() error {
+	var errs []error
+	for _, core := range t.cores {
+		if err := core.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"service/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type credential struct {
+	User     string
+	Password string
+}
+
+func (c credential) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", c.User)
+	enc.AddString("password", c.Password)
+	return nil
+}
+
+type credentials []credential
+
+func (cs credentials) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, c := range cs {
+		if err := enc.AppendObject(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRedactFields(t *testing.T) {
+	r, err := newRedactor(config.Redact{
+		Fields:   []string{"password", "request.token"},
+		Patterns: []string{`\d{11}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactTee([]zapcore.Core{core}, r))
+
+	log.Info("手机号 13800000000",
+		zap.String("password", "p1"),
+		zap.String("phone", "13800000000"),
+		zap.Error(errors.New("用户 13800000000 不存在")),
+		zap.Strings("phones", []string{"13800000000", "abc"}),
+		zap.Any("list", []string{"13800000000"}),
+		zap.Object("user", credential{User: "u", Password: "p2"}),
+		zap.Array("users", credentials{{User: "u", Password: "p3"}}),
+		zap.Any("request", map[string]any{"token": "t", "password": "p4", "name": "n"}),
+	)
+
+	entry := logs.All()[0]
+	got, err := json.Marshal(entry.ContextMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"error":"用户 ****** 不存在","list":["******"],"password":"******","phone":"******","phones":["******","abc"],` +
+		`"request":{"name":"n","password":"******","token":"******"},"user":{"password":"******","user":"u"},"users":[{"password":"******","user":"u"}]}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if entry.Message != "手机号 ******" {
+		t.Errorf("message got %q", entry.Message)
+	}
+}
+
+// countingJSON 记录 JSON 序列化次数
+type countingJSON struct {
+	calls *int
+}
+
+func (c countingJSON) MarshalJSON() ([]byte, error) {
+	*c.calls++
+	return []byte(`{"id":1}`), nil
+}
+
+func TestRedactOncePerEntry(t *testing.T) {
+	r, err := newRedactor(config.Redact{Fields: []string{"password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	debugCore, debugLogs := observer.New(zapcore.DebugLevel)
+	errorCore, errorLogs := observer.New(zapcore.ErrorLevel)
+	log := zap.New(newRedactTee([]zapcore.Core{debugCore, errorCore}, r))
+
+	calls := 0
+	log.Error("请求", zap.Any("request", countingJSON{calls: &calls}))
+	if calls != 1 {
+		t.Errorf("写入两个输出的日志应只脱敏一次, 序列化 %d 次", calls)
+	}
+	if debugLogs.Len() != 1 || errorLogs.Len() != 1 {
+		t.Fatalf("两个输出都应写入, got %d, %d", debugLogs.Len(), errorLogs.Len())
+	}
+
+	// 低于输出最低级别的日志不写入该输出
+	log.Info("请求")
+	if debugLogs.Len() != 2 || errorLogs.Len() != 1 {
+		t.Errorf("info 只应写入 debug 输出, got %d, %d", debugLogs.Len(), errorLogs.Len())
+	}
+}
+
+func TestRedactKeepsLargeIntegers(t *testing.T) {
+	r, err := newRedactor(config.Redact{Fields: []string{"password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactTee([]zapcore.Core{core}, r))
+
+	log.Info("请求", zap.Any("request", map[string]any{"id": uint64(1<<60 + 1), "password": "p"}))
+	got, err := json.Marshal(logs.All()[0].ContextMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"request":{"id":1152921504606846977,"password":"******"}}`; string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"service/config"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultReportInterval = time.Minute
+
+var (
+	sampledDropped atomic.Uint64 // 采样丢弃的日志条数
+	stopReport     chan struct{}
+	reportDone     chan struct{}
+)
+
+// newSampler 按消息采样, 每秒每条消息先记录 Initial 条, 之后每 Thereafter 条记录一条
+func newSampler(core zapcore.Core, cfg config.Sampling) zapcore.Core {
+	if cfg.Initial <= 0 {
+		return core
+	}
+	return zapcore.NewSamplerWithOptions(core, time.Second, cfg.Initial, cfg.Thereafter,
+		zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				sampledDropped.Add(1)
+			}
+		}),
+	)
+}
+
+// startDropReport 定期记录采样和缓冲写满丢弃的日志条数, 保证日志量仍然可见
+func startDropReport(cfg config.Sampling) {
+	interval := defaultReportInterval
+	if cfg.ReportInterval > 0 {
+		interval = time.Duration(cfg.ReportInterval) * time.Second
+	}
+	stopReport = make(chan struct{})
+	reportDone = make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				reportDropped()
+			case <-stop:
+				reportDropped()
+				return
+			}
+		}
+	}(stopReport, reportDone)
+}
+
+func reportDropped() {
+	if dropped := sampledDropped.Swap(0); dropped > 0 {
+		Warn(context.Background(), "日志采样丢弃", zap.Uint64("dropped", dropped))
+	}
+	if dropped := bufferDropped.Swap(0); dropped > 0 {
+		Warn(context.Background(), "日志缓冲已满丢弃", zap.Uint64("dropped", dropped))
+	}
+}
+
+// stopDropReport 停止定期汇总并输出剩余的丢弃条数
+func stopDropReport() {
+	if stopReport != nil {
+		close(stopReport)
+		<-reportDone
+		stopReport = nil
+	}
+}
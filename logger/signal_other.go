@@ -0,0 +1,14 @@
+//go:build !unix
+
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// ListenSignals 当前平台不支持 SIGUSR1/SIGUSR2, 仅可通过管理接口调整日志级别
+func ListenSignals(ctx context.Context, revertAfter time.Duration) {}
+
+// StopSignals 当前平台无需处理
+func StopSignals() {}
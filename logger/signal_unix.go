@@ -0,0 +1,42 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var signals chan os.Signal
+
+// ListenSignals 监听信号调整日志级别: SIGUSR1 切换为 debug, SIGUSR2 恢复为配置级别
+// revertAfter 大于 0 时 SIGUSR1 的调整到期自动恢复
+func ListenSignals(ctx context.Context, revertAfter time.Duration) {
+	signals = make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func(ch chan os.Signal) {
+		for sig := range ch {
+			if sig == syscall.SIGUSR1 {
+				_ = OverrideLevel(zapcore.DebugLevel.String(), revertAfter)
+			} else {
+				ResetLevel()
+			}
+			Info(ctx, "收到信号调整日志级别", zap.String("signal", sig.String()), zap.String("level", level.Level().String()))
+		}
+	}(signals)
+}
+
+// StopSignals 停止监听日志级别信号
+func StopSignals() {
+	if signals != nil {
+		signal.Stop(signals)
+		close(signals)
+		signals = nil
+	}
+}
@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"service/config"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志输出类型
+const (
+	SinkFile   = "file"
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkSyslog = "syslog"
+	SinkTCP    = "tcp"
+	SinkUDP    = "udp"
+)
+
+var (
+	closers       []io.Closer   // 需要在退出时关闭的日志输出
+	bufferDropped atomic.Uint64 // 缓冲写满丢弃的日志条数
+)
+
+// newSinkCore 根据输出类型创建 core, syslog 需要按日志级别写入, 不经过写入器和异步缓冲, 自带发送队列
+func newSinkCore(cfg config.Zap, sink config.Sink) (zapcore.Core, error) {
+	enc := getEncoder(sinkFormat(cfg, sink))
+	if sink.Type == SinkSyslog {
+		w, err := newSyslogWriter(sink.Network, sink.Address, sink.Tag, sink.BufferSize)
+		if err != nil {
+			return nil, fmt.Errorf("连接 syslog 失败: %w", err)
+		}
+		closers = append(closers, w)
+		return &leveledCore{LevelEnabler: sinkLevel(sink.Level), enc: enc, out: w}, nil
+	}
+	writer, err := newSinkWriter(cfg, sink)
+	if err != nil {
+		return nil, err
+	}
+	return zapcore.NewCore(enc, writer, sinkLevel(sink.Level)), nil
+}
+
+// newSinkWriter 根据输出类型创建写入器, 开启异步写入时包装为异步写入器
+func newSinkWriter(cfg config.Zap, sink config.Sink) (zapcore.WriteSyncer, error) {
+	writer, err := newBaseWriter(cfg, sink)
+	if err != nil {
+		return nil, err
+	}
+	// 网络输出自带缓冲
+	if !cfg.Async.Enabled || sink.Type == SinkTCP || sink.Type == SinkUDP {
+		return writer, nil
+	}
+	async := newAsyncWriter(writer, cfg.Async.BufferSize, time.Duration(cfg.Async.FlushInterval)*time.Millisecond, cfg.Async.Overflow)
+	closers = append(closers, async)
+	return async, nil
+}
+
+// newBaseWriter 根据输出类型创建同步写入器
+func newBaseWriter(cfg config.Zap, sink config.Sink) (zapcore.WriteSyncer, error) {
+	switch sink.Type {
+	case "", SinkFile:
+		return getLogWriter(
+			path.Join(cfg.Director, sink.Filename),
+			orDefault(sink.MaxSize, cfg.MaxSize),
+			orDefault(sink.MaxBackups, cfg.MaxBackups),
+			orDefault(sink.MaxAge, cfg.MaxAge),
+		), nil
+	case SinkStdout:
+		return zapcore.Lock(os.Stdout), nil
+	case SinkStderr:
+		return zapcore.Lock(os.Stderr), nil
+	case SinkTCP, SinkUDP:
+		w := newNetWriter(sink.Type, sink.Address, sink.BufferSize)
+		closers = append(closers, w)
+		return w, nil
+	}
+	return nil, fmt.Errorf("不支持的日志输出类型: %s", sink.Type)
+}
+
+// sinkFormat 获取输出格式, 网络输出默认使用 JSON
+func sinkFormat(cfg config.Zap, sink config.Sink) string {
+	if sink.Format != "" {
+		return sink.Format
+	}
+	if sink.Type == SinkTCP || sink.Type == SinkUDP {
+		return "json"
+	}
+	return cfg.Format
+}
+
+// hasFileSink 是否存在文件输出, 仅在需要时创建日志目录
+func hasFileSink(sinks []config.Sink) bool {
+	for _, sink := range sinks {
+		if sink.Type == "" || sink.Type == SinkFile {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStdoutSink 是否已配置控制台输出, 避免 debug 模式重复输出
+func hasStdoutSink(sinks []config.Sink) bool {
+	for _, sink := range sinks {
+		if sink.Type == SinkStdout {
+			return true
+		}
+	}
+	return false
+}
+
+// leveledWriter 按日志级别写入的输出, 如 syslog
+type leveledWriter interface {
+	io.Closer
+	WriteLevel(level zapcore.Level, p []byte) error
+	Sync() error
+}
+
+// leveledCore 编码后连同日志级别一起写入 leveledWriter
+type leveledCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out leveledWriter
+}
+
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &leveledCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), out: c.out}
+	for _, field := range fields {
+		field.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *leveledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *leveledCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	err = c.out.WriteLevel(ent.Level, buf.Bytes())
+	buf.Free()
+	return err
+}
+
+func (c *leveledCore) Sync() error {
+	return c.out.Sync()
+}
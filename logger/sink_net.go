@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	defaultNetBufferSize = 1024
+	netDialTimeout       = 3 * time.Second
+	netWriteTimeout      = 3 * time.Second
+	netSyncTimeout       = 3 * time.Second
+	netMinBackoff        = 100 * time.Millisecond
+	netMaxBackoff        = 30 * time.Second
+)
+
+var errNetSyncTimeout = errors.New("等待日志发送超时")
+
+// netWriter 通过 TCP/UDP 发送日志, 每条日志一行
+// 写入只进入缓冲队列, 由后台协程发送; 连接断开时按退避重连, 缓冲写满时丢弃并计数
+type netWriter struct {
+	network string
+	address string
+	conn    net.Conn
+	pending []byte // 发送失败等待重试的日志, 仅由后台协程访问
+	queue   chan []byte
+	flush   chan chan error
+	done    chan struct{}
+	exited  chan struct{}
+}
+
+func newNetWriter(network, address string, bufferSize int) *netWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultNetBufferSize
+	}
+	w := &netWriter{
+		network: network,
+		address: address,
+		queue:   make(chan []byte, bufferSize),
+		flush:   make(chan chan error),
+		done:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 写入缓冲队列, zap 会复用 p, 需要复制
+func (w *netWriter) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+	select {
+	case w.queue <- b:
+	default:
+		bufferDropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Sync 等待缓冲队列发送完毕, 采集端不可用时返回发送错误, 最多等待 netSyncTimeout
+func (w *netWriter) Sync() error {
+	timer := time.NewTimer(netSyncTimeout)
+	defer timer.Stop()
+	ack := make(chan error, 1)
+	select {
+	case w.flush <- ack:
+	case <-w.exited:
+		return nil
+	case <-timer.C:
+		return errNetSyncTimeout
+	}
+	select {
+	case err := <-ack:
+		return err
+	case <-timer.C:
+		return errNetSyncTimeout
+	}
+}
+
+// Close 尝试发送剩余日志并断开连接, 采集端不可用时剩余日志丢弃
+func (w *netWriter) Close() error {
+	close(w.done)
+	<-w.exited
+	return nil
+}
+
+// run 后台发送日志, 发送失败时按退避重试, 等待重试期间仍响应刷新和关闭
+func (w *netWriter) run() {
+	defer close(w.exited)
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+	var retry <-chan time.Time
+	backoff := netMinBackoff
+	for {
+		queue := w.queue
+		if w.pending != nil {
+			queue = nil // 等待重试期间暂停读取队列, 保持日志顺序
+		}
+		select {
+		case b := <-queue:
+			w.pending = b
+		case <-retry:
+		case ack := <-w.flush:
+			err := w.drain()
+			if err == nil {
+				retry, backoff = nil, netMinBackoff
+			} else if retry == nil {
+				retry = time.After(backoff)
+			}
+			ack <- err
+			continue
+		case <-w.done:
+			_ = w.drain()
+			return
+		}
+		if err := w.write(w.pending); err != nil {
+			retry = time.After(backoff)
+			backoff = min(backoff*2, netMaxBackoff)
+			continue
+		}
+		w.pending, retry, backoff = nil, nil, netMinBackoff
+	}
+}
+
+// drain 发送待重试的日志和队列中剩余的日志, 失败时立即返回, 不等待重连
+func (w *netWriter) drain() error {
+	for {
+		if w.pending == nil {
+			select {
+			case b := <-w.queue:
+				w.pending = b
+			default:
+				return nil
+			}
+		}
+		if err := w.write(w.pending); err != nil {
+			return err
+		}
+		w.pending = nil
+	}
+}
+
+func (w *netWriter) write(b []byte) error {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, netDialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(netWriteTimeout))
+	if _, err := w.conn.Write(b); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// acceptLines 接收 TCP 连接并把收到的每行日志发送到 lines
+func acceptLines(ln net.Listener, lines chan<- string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+	}
+}
+
+func waitLine(t *testing.T, lines <-chan string, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if line == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("未收到日志 %q", want)
+		}
+	}
+}
+
+func TestNetWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 16)
+	go acceptLines(ln, lines)
+
+	w := newNetWriter("tcp", ln.Addr().String(), 16)
+	defer w.Close()
+	_, _ = w.Write([]byte("line 1\n"))
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	waitLine(t, lines, "line 1")
+}
+
+func TestNetWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w := newNetWriter("udp", conn.LocalAddr().String(), 16)
+	defer w.Close()
+	_, _ = w.Write([]byte("line 1\n"))
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "line 1\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestNetWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	lines := make(chan string, 64)
+	go acceptLines(ln, lines)
+
+	w := newNetWriter("tcp", addr, 64)
+	defer w.Close()
+	_, _ = w.Write([]byte("before\n"))
+	waitLine(t, lines, "before")
+
+	// 重启采集端, 已建立的连接随之断开
+	_ = ln.Close()
+	restarted, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("无法在原地址重新监听: %v", err)
+	}
+	defer restarted.Close()
+	go acceptLines(restarted, lines)
+
+	// 断开后的第一次写入可能成功写进旧连接, 持续写入直到新连接收到
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		_, _ = w.Write([]byte("after\n"))
+		select {
+		case line := <-lines:
+			if line == "after" {
+				return
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("重启采集端后未重连")
+}
+
+func TestNetWriterDropsWhenFull(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close() // 采集端不可用
+
+	before := bufferDropped.Load()
+	w := newNetWriter("tcp", addr, 2)
+	defer w.Close()
+	for i := 0; i < 10; i++ {
+		_, _ = w.Write([]byte("line\n"))
+	}
+	// 最多 1 条等待重试, 2 条在缓冲中
+	if dropped := bufferDropped.Load() - before; dropped < 7 {
+		t.Fatalf("dropped = %d, want >= 7", dropped)
+	}
+
+	// 采集端不可用时 Sync 返回发送错误, 不会阻塞
+	start := time.Now()
+	if err := w.Sync(); err == nil {
+		t.Fatal("采集端不可用时 Sync 应返回错误")
+	}
+	if cost := time.Since(start); cost > netSyncTimeout {
+		t.Fatalf("Sync 耗时 %s", cost)
+	}
+}
+
+func TestNetWriterCloseWhileCollectorDown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	w := newNetWriter("tcp", addr, 16)
+	_, _ = w.Write([]byte(strings.Repeat("x", 10) + "\n"))
+	done := make(chan struct{})
+	go func() {
+		_ = w.Sync()
+		_ = w.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * netSyncTimeout):
+		t.Fatal("采集端不可用时 Close 阻塞")
+	}
+}
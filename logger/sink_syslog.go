@@ -0,0 +1,89 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 本机 syslog 的 unix 套接字, 与 log/syslog 的查找顺序一致
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogWriter 按日志级别格式化为 syslog 消息, 经 netWriter 的缓冲队列发送
+// 采集端阻塞时写入不等待, 缓冲写满后丢弃并计数, 每次发送都有写超时
+type syslogWriter struct {
+	*netWriter
+	tag      string
+	hostname string // 为空表示本机 syslog, 消息中不带主机名
+	pid      int
+}
+
+// newSyslogWriter 创建 syslog 输出, network 为空时写入本机 syslog
+// 远程 syslog 与 tcp、udp 输出一样在发送时才连接, 本机 syslog 在创建时查找可用的套接字
+func newSyslogWriter(network, address, tag string, bufferSize int) (leveledWriter, error) {
+	if tag == "" {
+		tag = os.Args[0]
+	}
+	w := &syslogWriter{tag: tag, pid: os.Getpid()}
+	if network == "" {
+		network, address = localSyslog()
+		if address == "" {
+			return nil, errors.New("未找到本机 syslog 套接字")
+		}
+	} else {
+		w.hostname, _ = os.Hostname()
+		if w.hostname == "" {
+			w.hostname = "localhost"
+		}
+	}
+	w.netWriter = newNetWriter(network, address, bufferSize)
+	return w, nil
+}
+
+// localSyslog 查找本机 syslog 的套接字, 找不到时 address 为空
+func localSyslog() (network, address string) {
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range syslogSockets {
+			conn, err := net.DialTimeout(network, path, netDialTimeout)
+			if err == nil {
+				_ = conn.Close()
+				return network, path
+			}
+		}
+	}
+	return "", ""
+}
+
+// WriteLevel error 及以上写入 LOG_ERR, warn 写入 LOG_WARNING, info 写入 LOG_INFO, debug 写入 LOG_DEBUG
+func (w *syslogWriter) WriteLevel(level zapcore.Level, p []byte) error {
+	severity := syslog.LOG_DEBUG
+	switch {
+	case level >= zapcore.ErrorLevel:
+		severity = syslog.LOG_ERR
+	case level == zapcore.WarnLevel:
+		severity = syslog.LOG_WARNING
+	case level == zapcore.InfoLevel:
+		severity = syslog.LOG_INFO
+	}
+	_, err := w.Write(w.format(syslog.LOG_LOCAL0|severity, string(p)))
+	return err
+}
+
+// format 按 log/syslog 的格式拼接消息, 远程 syslog 带主机名和 RFC3339 时间
+func (w *syslogWriter) format(priority syslog.Priority, msg string) []byte {
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	if w.hostname == "" {
+		return []byte(fmt.Sprintf("<%d>%s %s[%d]: %s", priority, time.Now().Format(time.Stamp), w.tag, w.pid, msg))
+	}
+	return []byte(fmt.Sprintf("<%d>%s %s %s[%d]: %s", priority, time.Now().Format(time.RFC3339), w.hostname, w.tag, w.pid, msg))
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+import "errors"
+
+// newSyslogWriter 当前平台不支持 syslog
+func newSyslogWriter(network, address, tag string, bufferSize int) (leveledWriter, error) {
+	return nil, errors.New("当前平台不支持 syslog")
+}
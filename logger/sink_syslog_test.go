@@ -0,0 +1,99 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSyslogSeverity(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := newSyslogWriter("udp", conn.LocalAddr().String(), "service", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	core := &leveledCore{LevelEnabler: zapcore.DebugLevel, enc: getEncoder("json"), out: w}
+	log := zap.New(core)
+
+	// LOG_LOCAL0 为 16, 优先级 = 16*8 + 级别
+	tests := []struct {
+		level    zapcore.Level
+		priority string
+	}{
+		{zapcore.DebugLevel, "<135>"}, // LOG_DEBUG
+		{zapcore.InfoLevel, "<134>"},  // LOG_INFO
+		{zapcore.WarnLevel, "<132>"},  // LOG_WARNING
+		{zapcore.ErrorLevel, "<131>"}, // LOG_ERR
+	}
+	buf := make([]byte, 2048)
+	for _, tt := range tests {
+		log.Log(tt.level, "hello")
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); !strings.HasPrefix(got, tt.priority) {
+			t.Errorf("%s: got %q, want prefix %s", tt.level, got, tt.priority)
+		}
+	}
+}
+
+// 远程 syslog 不读取数据时, 写日志不能阻塞, 写满缓冲后丢弃
+func TestSyslogStalledTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	stalled := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			stalled <- conn // 只接收连接, 不读取
+		}
+	}()
+
+	w, err := newSyslogWriter("tcp", ln.Addr().String(), "service", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	core := &leveledCore{LevelEnabler: zapcore.DebugLevel, enc: getEncoder("json"), out: w}
+	log := zap.New(core)
+
+	dropped := bufferDropped.Load()
+	msg := strings.Repeat("x", 64*1024)
+	start := time.Now()
+	for range 1000 {
+		log.Info(msg)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("写入阻塞 %v", elapsed)
+	}
+	if bufferDropped.Load() == dropped {
+		t.Error("缓冲写满后应丢弃日志")
+	}
+
+	// 发送受写超时限制, 关闭不会一直等待
+	start = time.Now()
+	_ = w.Close()
+	if elapsed := time.Since(start); elapsed > 2*netWriteTimeout {
+		t.Fatalf("关闭等待 %v", elapsed)
+	}
+	select {
+	case conn := <-stalled:
+		conn.Close()
+	default:
+	}
+}
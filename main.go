@@ -3,16 +3,24 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"service/config"
+	"service/health"
 	"service/logger"
 	"service/middleware"
 	"service/redis"
+	"service/reqctx"
 	"service/router"
+	"service/shutdown"
+	"service/tracing"
 	"service/translator"
+	"service/version"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,6 +29,20 @@ import (
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(checkConfig(os.Args[2:]))
+	}
+
+	// 解析命令行参数
+	config.BindFlags(flag.CommandLine)
+	showVersion := flag.Bool("version", false, "打印版本信息")
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(version.Get())
+		return
+	}
+
 	// 初始化各个模块
 	if err := initModules(); err != nil {
 		fmt.Printf("初始化失败:%v\n", err)
@@ -30,29 +52,62 @@ func main() {
 	// 关闭控制台颜色
 	gin.DisableConsoleColor()
 	// 设置模式
-	gin.SetMode(config.AppConfig.Debug)
+	gin.SetMode(config.Get().Debug)
 
-	// 开启gin实例
+	// 开启gin实例, 上下文回退到 Request 的上下文, 使 reqctx 中的值对 gin.Context 同样可见
 	r := gin.New()
+	r.ContextWithFallback = true
+	// 存活、就绪检查在中间件之前注册, 不受限流影响
+	router.Health(r)
 	setupMiddleware(r)
 
 	// HTTP配置
 	server := &http.Server{
-		Addr:           fmt.Sprintf(":%d", config.AppConfig.Port),
+		Addr:           fmt.Sprintf(":%d", config.Get().Port),
 		Handler:        router.Route(r),
 		ReadTimeout:    5 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	ctx, cancel := createContextWithTraceID()
-	defer redis.Close(ctx) // 在服务关闭时断开 Redis 连接
-	defer cancel()
+	// 管理端口, 未配置端口和 socket 时不开启
+	var adminServer *http.Server
+	if admin := config.Get().Admin; admin.Port != 0 || admin.Socket != "" {
+		adminEngine := gin.New()
+		adminEngine.ContextWithFallback = true
+		adminEngine.Use(middleware.Trace(), middleware.Error())
+		adminServer = &http.Server{
+			Addr:           fmt.Sprintf(":%d", admin.Port),
+			Handler:        router.AdminListener(adminEngine, r),
+			ReadTimeout:    5 * time.Second,
+			WriteTimeout:   2 * time.Minute, // pprof 采样时长需小于写超时
+			MaxHeaderBytes: 1 << 20,
+		}
+	}
+
+	ctx := createContextWithTraceID()
+
+	// 监听配置文件变更
+	watchConfig(ctx)
+
+	// 监听信号调整日志级别
+	logger.ListenSignals(ctx, time.Duration(config.Get().Zap.RevertMinutes)*time.Minute)
 
 	// 开启服务
-	go func() {
-		startServer(ctx, server)
-	}()
+	listener, err := listenTCP(config.Get().Port)
+	if err != nil {
+		logger.Fatal(ctx, "服务监听失败", zap.Error(err))
+	}
+	go startServer(ctx, server, listener)
+	var adminListener net.Listener
+	if adminServer != nil {
+		if adminListener, err = listenAdmin(config.Get().Admin); err != nil {
+			logger.Error(ctx, "管理端口监听失败", zap.Error(err))
+			adminServer = nil
+		} else {
+			go startServer(ctx, adminServer, adminListener)
+		}
+	}
 
 	// 优雅Shutdown（或重启）服务
 	quit := make(chan os.Signal, 1)
@@ -60,11 +115,64 @@ func main() {
 	<-quit
 	logger.Info(ctx, "关闭服务...")
 
-	// 关闭服务
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal(ctx, "服务关闭原因:", zap.Error(err))
+	// 按顺序关闭, 每个阶段单独计时
+	cfg := config.Get().Shutdown
+	delay := time.Duration(cfg.Delay) * time.Second
+	orchestrator := shutdown.New()
+	orchestrator.Add("停止就绪", delay+shutdown.DefaultStageTimeout, func(ctx context.Context) error {
+		// 就绪检查返回不可用, 等待负载均衡撤走流量
+		health.SetShuttingDown()
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	orchestrator.Add("停止接收新连接", 0, func(ctx context.Context) error {
+		server.SetKeepAlivesEnabled(false)
+		err := listener.Close()
+		if adminListener != nil {
+			err = errors.Join(err, adminListener.Close())
+		}
+		return err
+	})
+	orchestrator.Add("等待请求完成", time.Duration(cfg.Timeout)*time.Second, func(ctx context.Context) error {
+		servers := []*http.Server{server}
+		if adminServer != nil {
+			servers = append(servers, adminServer)
+		}
+		var errs []error
+		for _, s := range servers {
+			if err := s.Shutdown(ctx); err != nil {
+				// 超时后强制断开剩余连接, 避免后续阶段关闭依赖时请求仍在执行
+				errs = append(errs, err, s.Close())
+			}
+		}
+		return errors.Join(errs...)
+	})
+	orchestrator.Add("停止后台任务", 0, func(ctx context.Context) error {
+		logger.StopSignals()
+		return config.StopWatch()
+	})
+	orchestrator.Add("关闭 Redis", 0, func(ctx context.Context) error {
+		redis.Close(ctx)
+		return nil
+	})
+	orchestrator.Add("导出链路追踪", 0, tracing.Shutdown)
+	err = orchestrator.Shutdown(ctx)
+
+	// 最后刷新日志, 保证各阶段和关闭汇总的日志都能写出, 之后无法再记录日志
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdown.DefaultStageTimeout)
+	defer cancel()
+	if closeErr := logger.Close(flushCtx); closeErr != nil {
+		fmt.Fprintln(os.Stderr, closeErr)
+		err = errors.Join(err, closeErr)
+	}
+	// 有阶段失败时以非0状态退出, 失败原因已在各阶段记录
+	if err != nil {
+		cancel()
+		os.Exit(1)
 	}
-	logger.Info(ctx, "服务退出")
 }
 
 // 初始化模块
@@ -77,6 +185,17 @@ func initModules() error {
 		return fmt.Errorf("初始化日志异常: %v", err)
 	}
 	fmt.Println("日志初始化成功")
+	v := version.Get()
+	logger.Info(context.Background(), "服务版本 "+v.Version,
+		zap.String("commit", v.Commit),
+		zap.String("build_time", v.BuildTime),
+		zap.String("go_version", v.GoVersion),
+		zap.Bool("dirty", v.Dirty),
+	)
+	if err := tracing.InitTracing(); err != nil {
+		return fmt.Errorf("链路追踪初始化失败: %v", err)
+	}
+	fmt.Println("链路追踪初始化成功")
 	if err := redis.InitRedis(); err != nil {
 		return fmt.Errorf("redis 初始化失败: %v", err)
 	}
@@ -87,15 +206,29 @@ func initModules() error {
 	fmt.Println("翻译器初始化成功")
 	middleware.InitLimiter()
 	fmt.Println("限流器初始化成功")
-	middleware.InitAllowedOrigins(config.AppConfig.AllowOrigins)
+	middleware.InitAllowedOrigins(config.Get().AllowOrigins)
 	fmt.Println("跨域初始化成功")
 	return nil
 }
 
+// 校验配置文件, 用法: service check-config [-config path] [-profile name]
+func checkConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ExitOnError)
+	config.BindFlags(fs)
+	_ = fs.Parse(args)
+	if err := config.Check(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Println("配置校验通过")
+	return 0
+}
+
 // 设置中间件
 func setupMiddleware(r *gin.Engine) {
 	r.Use(
 		middleware.Trace(),   // 跟踪
+		middleware.Tracing(), // 链路追踪
 		middleware.Cors(),    // 跨域处理
 		middleware.Limiter(), // 限流处理
 		middleware.Logger(),  // 日志处理
@@ -103,19 +236,78 @@ func setupMiddleware(r *gin.Engine) {
 	)
 }
 
-// 创建包含 Trace ID 的上下文
-func createContextWithTraceID() (context.Context, context.CancelFunc) {
+// 监听配置文件, 热加载后通知各模块
+func watchConfig(ctx context.Context) {
+	config.OnChange(func(cfg *config.Config) {
+		middleware.SetLimit(cfg.Limit)
+		middleware.InitAllowedOrigins(cfg.AllowOrigins)
+		logger.SetLevel(cfg.Zap.Level)
+		logger.Info(ctx, "配置热加载成功")
+	})
+	err := config.Watch(func(err error) {
+		logger.Error(ctx, "配置热加载失败", zap.Error(err))
+	})
+	if err != nil {
+		logger.Error(ctx, "配置监听启动失败", zap.Error(err))
+	}
+}
+
+// 创建包含 Trace ID 的上下文, 用于服务生命周期内的日志, 不设超时
+func createContextWithTraceID() context.Context {
 	baseCtx := context.Background()
 	traceID := fmt.Sprintf("main:date(%s)", time.Now().Format("2006-01-02 15:04:05"))
-	ctx := context.WithValue(baseCtx, "TraceID", traceID)
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	return ctx, cancel
+	return reqctx.WithTraceID(baseCtx, traceID)
 }
 
-// 启动 HTTP 服务器
-func startServer(ctx context.Context, server *http.Server) {
-	logger.Info(ctx, fmt.Sprintf("服务开启:%d", config.AppConfig.Port))
-	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+// 启动 HTTP 服务器, 监听器在关闭流程中先于 Shutdown 关闭, 此时的返回不视为异常
+func startServer(ctx context.Context, server *http.Server, listener net.Listener) {
+	logger.Info(ctx, fmt.Sprintf("服务开启:%s", listener.Addr()))
+	err := server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
 		logger.Fatal(ctx, "listen: %s\n", zap.Error(err))
 	}
 }
+
+// 监听 TCP 端口
+func listenTCP(port int) (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+	return &onceCloseListener{Listener: listener}, nil
+}
+
+// 监听管理端口, 配置了 unix socket 时监听 socket
+func listenAdmin(admin config.Admin) (net.Listener, error) {
+	if admin.Socket == "" {
+		return listenTCP(admin.Port)
+	}
+	// 清理上次异常退出残留的 socket 文件
+	if info, err := os.Lstat(admin.Socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(admin.Socket)
+	}
+	listener, err := net.Listen("unix", admin.Socket)
+	if err != nil {
+		return nil, err
+	}
+	// 仅允许当前用户访问
+	if err := os.Chmod(admin.Socket, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("设置 socket 权限失败: %w", err)
+	}
+	return &onceCloseListener{Listener: listener}, nil
+}
+
+// onceCloseListener 只关闭一次的监听器, 关闭流程先关闭监听器再调用 Shutdown, 避免重复关闭报错
+type onceCloseListener struct {
+	net.Listener
+	once sync.Once
+	err  error
+}
+
+func (l *onceCloseListener) Close() error {
+	l.once.Do(func() {
+		l.err = l.Listener.Close()
+	})
+	return l.err
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "service"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	// HTTP 请求数, 按路由、方法、状态码统计
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"route", "method", "status"})
+
+	// HTTP 请求耗时
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时(秒)",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// 被限流的请求数
+	limiterRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "limiter_rejected_total",
+		Help:      "被限流拒绝的请求数",
+	})
+
+	// 捕获的 panic 数
+	panicsRecovered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_recovered_total",
+		Help:      "异常中间件捕获的 panic 数",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		limiterRejected,
+		panicsRecovered,
+	)
+}
+
+// Register 注册自定义指标, 如 Redis 连接池
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 输出 Prometheus 格式的指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveHTTP 记录一次 HTTP 请求, route 为路由模板, 未匹配路由时传空
+func ObserveHTTP(route, method string, status int, cost time.Duration) {
+	// 未匹配的路径统一归类, 避免标签数量无限增长
+	if route == "" {
+		route = "unmatched"
+	}
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpDuration.WithLabelValues(route, method, code).Observe(cost.Seconds())
+}
+
+// IncLimiterRejected 记录一次限流拒绝
+func IncLimiterRejected() {
+	limiterRejected.Inc()
+}
+
+// IncPanicsRecovered 记录一次捕获的 panic
+func IncPanicsRecovered() {
+	panicsRecovered.Inc()
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"service/config"
 	"service/constant"
+	"service/reqctx"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +14,26 @@ import (
 func Auth() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		clientID := ctx.Request.Header.Get("clientId")
-		if clientID != "tC0ND8ar26Jk9L5b" {
+		expected := config.Get().Auth.ClientID.Value()
+		if subtle.ConstantTimeCompare([]byte(clientID), []byte(expected)) != 1 {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"code": constant.FORBIDDEN,
+				"msg":  "无权限",
+			})
+			ctx.Abort()
+			return
+		}
+		reqctx.WithClientID(ctx, clientID)
+		ctx.Next()
+	}
+}
+
+// AdminAuth 管理接口鉴权, 校验 Authorization: Bearer <token>, 未配置令牌时拒绝所有请求
+func AdminAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := strings.TrimPrefix(ctx.Request.Header.Get("Authorization"), "Bearer ")
+		expected := config.Get().Admin.Token.Value()
+		if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
 			ctx.JSON(http.StatusForbidden, gin.H{
 				"code": constant.FORBIDDEN,
 				"msg":  "无权限",
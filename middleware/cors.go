@@ -3,17 +3,20 @@ package middleware
 import (
 	"net/http"
 	"service/config"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 )
 
-var allowedOriginsMap map[string]struct{}
+var allowedOriginsMap atomic.Pointer[map[string]struct{}]
 
+// InitAllowedOrigins 初始化允许跨域的origin, 配置热加载时整体替换
 func InitAllowedOrigins(origins []string) {
-	allowedOriginsMap = make(map[string]struct{}, len(origins))
+	originsMap := make(map[string]struct{}, len(origins))
 	for _, origin := range origins {
-		allowedOriginsMap[origin] = struct{}{}
+		originsMap[origin] = struct{}{}
 	}
+	allowedOriginsMap.Store(&originsMap)
 }
 
 // Cors 中间件处理跨域请求
@@ -29,7 +32,7 @@ func Cors() gin.HandlerFunc {
 		}
 
 		// 调试模式下放行所有请求
-		if config.AppConfig.Debug == "debug" {
+		if config.Get().Debug == "debug" {
 			ctx.Next()
 			return
 		}
@@ -53,8 +56,8 @@ func Cors() gin.HandlerFunc {
 func setHeaders(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", ctx.GetHeader("Origin"))
 	ctx.Header("Access-Control-Allow-Methods", "POST,GET,OPTIONS,DELETE,PUT")
-	ctx.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token,Authorization,Token,X-Token,X-User-Id")
-	ctx.Header("Access-Control-Expose-Headers", "Content-Length,Access-Control-Allow-Origin,Access-Control-Allow-Headers,Content-Type,New-Token,New-Expires-At")
+	ctx.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token,Authorization,Token,X-Token,X-User-Id,X-Request-Id,traceparent,tracestate")
+	ctx.Header("Access-Control-Expose-Headers", "Content-Length,Access-Control-Allow-Origin,Access-Control-Allow-Headers,Content-Type,New-Token,New-Expires-At,X-Trace-Id,traceparent")
 	ctx.Header("Access-Control-Allow-Credentials", "true")
 	ctx.Header("Access-Control-Max-Age", "86400")
 }
@@ -62,6 +65,6 @@ func setHeaders(ctx *gin.Context) {
 // allowOrigins 校验请求的来源是否在允许的列表中
 func allowOrigins(ctx *gin.Context) bool {
 	origin := ctx.GetHeader("Origin")
-	_, exists := allowedOriginsMap[origin]
+	_, exists := (*allowedOriginsMap.Load())[origin]
 	return exists
 }
@@ -3,11 +3,11 @@ package middleware
 import (
 	"net"
 	"net/http"
-	"net/http/httputil"
 	"os"
 	"runtime/debug"
 	"service/constant"
 	"service/logger"
+	"service/metrics"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +19,7 @@ func Error() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				metrics.IncPanicsRecovered()
 				var brokenPipe bool
 				if ne, ok := err.(*net.OpError); ok {
 					if se, ok := ne.Err.(*os.SyscallError); ok {
@@ -28,16 +29,17 @@ func Error() gin.HandlerFunc {
 					}
 				}
 
-				// 获取请求信息
-				httpRequest, _ := httputil.DumpRequest(ctx.Request, false)
+				// 获取请求信息, 敏感请求头已脱敏
+				httpRequest := logger.DumpRequest(ctx.Request)
+				logCtx := logger.WithCategory(ctx, logger.CategoryError)
 
 				// 处理断开连接情况
 				if brokenPipe {
-					logger.Error(ctx,
+					logger.Error(logCtx,
 						"请求连接断开",
 						zap.String("url", ctx.Request.URL.Path),
 						zap.Any("error", err),
-						zap.String("request", string(httpRequest)),
+						zap.String("request", httpRequest),
 					)
 					ctx.JSON(http.StatusOK, gin.H{
 						"code": constant.ERROR,
@@ -48,11 +50,11 @@ func Error() gin.HandlerFunc {
 				}
 
 				// 记录异常日志和堆栈信息
-				logger.Error(ctx,
+				logger.Error(logCtx,
 					"异常捕获",
 					zap.String("type", "server_error"),
 					zap.Any("error", err),
-					zap.String("request", strings.ReplaceAll(string(httpRequest), "\r\n", " ")),
+					zap.String("request", strings.ReplaceAll(httpRequest, "\r\n", " ")),
 					zap.Strings("stack", formatStackTrace()),
 				)
 
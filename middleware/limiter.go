@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"service/config"
 	"service/logger"
+	"service/metrics"
 	"sync"
 
 	"go.uber.org/zap"
@@ -20,14 +21,20 @@ var (
 // InitLimiter 初始化限流器
 func InitLimiter() {
 	once.Do(func() {
-		limiter = rate.NewLimiter(rate.Limit(config.AppConfig.Limit), 10)
+		limiter = rate.NewLimiter(rate.Limit(config.Get().Limit), 10)
 	})
 }
 
+// SetLimit 调整限流速率, 配置热加载时调用
+func SetLimit(limit float64) {
+	limiter.SetLimit(rate.Limit(limit))
+}
+
 func Limiter() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// 检查请求是否被限流
 		if !limiter.Allow() {
+			metrics.IncLimiterRejected()
 			logger.Warn(ctx, "请求被限流",
 				zap.String("url", ctx.Request.URL.Path),
 				zap.String("client_ip", ctx.ClientIP()),
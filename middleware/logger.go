@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"service/logger"
+	"service/metrics"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,7 @@ func Logger() gin.HandlerFunc {
 
 		cost := time.Since(start)
 		status := ctx.Writer.Status()
+		metrics.ObserveHTTP(ctx.FullPath(), ctx.Request.Method, status, cost)
 
 		// 构造日志信息
 		layout := LogLayout{
@@ -50,11 +52,12 @@ func Logger() gin.HandlerFunc {
 		}
 
 		msg := fmt.Sprintf("%d %v %s %s", status, cost.Milliseconds(), layout.Method, layout.Path)
+		logCtx := logger.WithCategory(ctx, logger.CategoryAccess)
 		// 根据响应状态码决定记录日志级别
 		if status >= 400 {
-			logger.Error(ctx, msg, zap.Any("log", layout))
+			logger.Error(logCtx, msg, zap.Any("log", layout))
 		} else {
-			logger.Info(ctx, msg, zap.Any("log", layout))
+			logger.Info(logCtx, msg, zap.Any("log", layout))
 		}
 	}
 }
@@ -1,14 +1,160 @@
 package middleware
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"service/config"
+	"service/logger"
+	"service/reqctx"
+	"service/tracing"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+	traceVersion      = "00"
+	traceSampled      = "01"
+	maxLegacyTraceID  = 128
 )
 
+// Trace 链路跟踪, 依次沿用 W3C traceparent、兼容请求头中的链路ID, 都没有时生成新的链路
+// 每个请求生成新的 span ID, 并通过响应头回传链路ID和 traceparent
 func Trace() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		traceID := uuid.New().String()
-		ctx.Set("TraceID", traceID)
+		cfg := config.Get().Trace
+		traceID, parentSpanID, traceFlags, ok := parseTraceparent(ctx.GetHeader(traceparentHeader))
+		traceState := ""
+		if ok {
+			traceState = ctx.GetHeader(tracestateHeader)
+		} else {
+			traceID, parentSpanID, traceFlags = legacyTraceID(ctx.GetHeader(cfg.Header)), "", traceSampled
+			if traceID == "" {
+				traceID = newID(16)
+			}
+		}
+		spanID := newID(8)
+
+		reqctx.WithTraceID(ctx, traceID)
+		reqctx.WithSpan(ctx, spanID, parentSpanID, traceFlags, traceState)
+		reqctx.WithStartTime(ctx, time.Now())
+
+		// 回传链路ID
+		if cfg.ResponseHeader != "" {
+			ctx.Header(cfg.ResponseHeader, traceID)
+		}
+		if traceparent, ok := Traceparent(ctx); ok {
+			ctx.Header(traceparentHeader, traceparent)
+		}
+
+		// 绑定日志字段, 后续日志无需每次附加
+		fields := []zap.Field{zap.String("trace_id", traceID), zap.String("span_id", spanID)}
+		if parentSpanID != "" {
+			fields = append(fields, zap.String("parent_span_id", parentSpanID))
+		}
+		logger.WithFields(ctx, fields...)
 		ctx.Next()
 	}
 }
+
+// Traceparent 按当前请求的链路生成 W3C traceparent, 用于向下游传递; 链路ID来自兼容请求头且不符合规范时返回 false
+func Traceparent(ctx context.Context) (string, bool) {
+	traceID, spanID := reqctx.TraceID(ctx), reqctx.SpanID(ctx)
+	if !isHex(traceID, 32) || !isHex(spanID, 16) {
+		return "", false
+	}
+	return strings.Join([]string{traceVersion, traceID, spanID, reqctx.TraceFlags(ctx)}, "-"), true
+}
+
+// parseTraceparent 解析 W3C traceparent: version-trace_id-parent_id-trace_flags
+func parseTraceparent(header string) (traceID, parentSpanID, traceFlags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return "", "", "", false
+	}
+	version, traceID, parentSpanID, traceFlags := parts[0], parts[1], parts[2], parts[3]
+	// 版本 00 只有四段, 更高版本允许追加字段
+	if !isHex(version, 2) || version == "ff" || version == traceVersion && len(parts) != 4 {
+		return "", "", "", false
+	}
+	if !isHex(traceID, 32) || !isHex(parentSpanID, 16) || !isHex(traceFlags, 2) {
+		return "", "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentSpanID, "0") == "" {
+		return "", "", "", false
+	}
+	return traceID, parentSpanID, traceFlags, true
+}
+
+// legacyTraceID 兼容请求头中的链路ID, 过长时截断
+func legacyTraceID(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > maxLegacyTraceID {
+		value = value[:maxLegacyTraceID]
+	}
+	return value
+}
+
+// isHex 判断是否为指定长度的小写十六进制
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newID 生成 n 字节的随机ID
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Tracing 为每个路由创建服务端 span, 需放在 Trace 之后; 未开启链路追踪时直接放行
+func Tracing() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !tracing.Enabled() {
+			ctx.Next()
+			return
+		}
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		spanCtx, span := tracing.StartServerSpan(ctx.Request.Context(), ctx.Request.Method+" "+route,
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.URLPathKey.String(ctx.Request.URL.Path),
+				semconv.ClientAddressKey.String(ctx.ClientIP()),
+			),
+		)
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCodeKey.Int(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if err := ctx.Errors.Last(); err != nil {
+			span.RecordError(err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package model
+
+type LogLevel struct {
+	Level   string `json:"level" binding:"required,oneof=debug info warn error dpanic panic fatal"`
+	Minutes int    `json:"minutes" binding:"gte=0"` // 自动恢复为配置级别的分钟数, 0 表示不自动恢复
+}
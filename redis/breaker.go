@@ -0,0 +1,253 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"service/config"
+	"service/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen 熔断中, 命令未发送直接失败, 可通过 errors.Is 判断
+var ErrCircuitOpen = errors.New("Redis 熔断中")
+
+// 熔断参数的默认值, 配置为0时使用
+const (
+	defaultBreakerFailures    = 5
+	defaultBreakerOpenTimeout = 10 * time.Second
+	defaultBreakerProbes      = 1
+)
+
+// breakerState 熔断状态
+type breakerState int32
+
+const (
+	breakerClosed   breakerState = iota // 关闭, 正常放行
+	breakerOpen                         // 开启, 直接失败
+	breakerHalfOpen                     // 半开, 放行少量探测
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker 单个客户端的熔断器, 以 go-redis hook 的方式包在命令外层
+// 连续失败或慢调用达到阈值后开启, 开启期间命令直接返回 ErrCircuitOpen,
+// 超过 openTimeout 后进入半开状态放行 probes 个探测, 全部成功则关闭, 任一失败则重新开启
+type breaker struct {
+	key           string
+	failures      int
+	slowThreshold time.Duration
+	openTimeout   time.Duration
+	probes        int
+
+	mu       sync.Mutex
+	state    breakerState
+	count    int       // closed: 连续失败次数; half_open: 成功的探测次数
+	inflight int       // half_open: 已放行的探测次数
+	openedAt time.Time // 最近一次开启的时间
+	gen      uint64    // 状态切换次数, 放行时作为凭证, 结果返回时状态已切换则不计入
+
+	rejected atomic.Uint64 // 熔断拒绝的命令数
+}
+
+func newBreaker(key string, cfg config.RedisBreaker) *breaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	b := &breaker{
+		key:           key,
+		failures:      cfg.Failures,
+		slowThreshold: time.Duration(cfg.SlowThreshold) * time.Millisecond,
+		openTimeout:   time.Duration(cfg.OpenTimeout) * time.Millisecond,
+		probes:        cfg.Probes,
+	}
+	if b.failures == 0 {
+		b.failures = defaultBreakerFailures
+	}
+	if b.openTimeout == 0 {
+		b.openTimeout = defaultBreakerOpenTimeout
+	}
+	if b.probes == 0 {
+		b.probes = defaultBreakerProbes
+	}
+	return b
+}
+
+func (b *breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ticket, err := b.allow()
+		if err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		start := time.Now()
+		err = next(ctx, cmd)
+		b.record(ticket, err, time.Since(start))
+		return err
+	}
+}
+
+func (b *breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ticket, err := b.allow()
+		if err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		start := time.Now()
+		err = next(ctx, cmds)
+		b.record(ticket, err, time.Since(start))
+		return err
+	}
+}
+
+// allow 判断是否放行, 开启超过 openTimeout 后转为半开
+// 放行时返回凭证(放行时的状态代数), 命令结束后连同结果交给 record
+func (b *breaker) allow() (uint64, error) {
+	b.mu.Lock()
+	change, err := b.allowLocked()
+	ticket := b.gen
+	b.mu.Unlock()
+	b.log(change)
+	return ticket, err
+}
+
+func (b *breaker) allowLocked() (stateChange, error) {
+	var change stateChange
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			break
+		}
+		change = b.transition(breakerHalfOpen)
+		fallthrough
+	case breakerHalfOpen:
+		if b.inflight < b.probes {
+			b.inflight++
+			return change, nil
+		}
+	default:
+		return change, nil
+	}
+	b.rejected.Add(1)
+	return change, fmt.Errorf("%w: %s", ErrCircuitOpen, b.key)
+}
+
+// record 记录命令结果, ticket 为放行时 allow 返回的凭证
+func (b *breaker) record(ticket uint64, err error, cost time.Duration) {
+	b.mu.Lock()
+	change := b.recordLocked(ticket, err, cost)
+	b.mu.Unlock()
+	b.log(change)
+}
+
+func (b *breaker) recordLocked(ticket uint64, err error, cost time.Duration) stateChange {
+	// 放行后状态已切换, 如半开时才返回的关闭期间的命令, 结果不代表当前状态, 不计入
+	if ticket != b.gen {
+		return stateChange{}
+	}
+	// 调用方取消的命令无法说明 Redis 是否可用, 不计入成功或失败, 半开状态下归还探测名额
+	if errors.Is(err, context.Canceled) {
+		if b.state == breakerHalfOpen && b.inflight > 0 {
+			b.inflight--
+		}
+		return stateChange{}
+	}
+	failed := isFailure(err) || (b.slowThreshold > 0 && cost >= b.slowThreshold)
+	switch b.state {
+	case breakerClosed:
+		if !failed {
+			b.count = 0
+			return stateChange{}
+		}
+		b.count++
+		if b.count >= b.failures {
+			return b.transition(breakerOpen)
+		}
+	case breakerHalfOpen:
+		if failed {
+			return b.transition(breakerOpen)
+		}
+		b.count++
+		if b.count >= b.probes {
+			return b.transition(breakerClosed)
+		}
+	}
+	return stateChange{}
+}
+
+// stateChange 状态变更, 在锁内记录, 释放锁后输出日志
+type stateChange struct {
+	changed  bool
+	from, to breakerState
+}
+
+// transition 切换状态, 调用方需持有锁
+func (b *breaker) transition(state breakerState) stateChange {
+	from := b.state
+	b.state = state
+	b.gen++
+	b.count = 0
+	b.inflight = 0
+	if state == breakerOpen {
+		b.openedAt = time.Now()
+	}
+	return stateChange{changed: true, from: from, to: state}
+}
+
+// log 记录状态变更日志, 不能持有锁调用, 避免日志输出阻塞时其他命令等待
+func (b *breaker) log(change stateChange) {
+	if !change.changed {
+		return
+	}
+	fields := []zap.Field{zap.String("client", b.key), zap.String("from", change.from.String())}
+	switch change.to {
+	case breakerOpen:
+		logger.Warn(context.Background(), "Redis 熔断开启", append(fields, zap.Duration("open_timeout", b.openTimeout))...)
+	case breakerHalfOpen:
+		logger.Info(context.Background(), "Redis 熔断半开, 放行探测", append(fields, zap.Int("probes", b.probes))...)
+	case breakerClosed:
+		logger.Info(context.Background(), "Redis 熔断恢复", fields...)
+	}
+}
+
+// currentState 当前状态, 用于指标
+func (b *breaker) currentState() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// isFailure 是否计为失败: 超时和网络错误计入, 服务端返回的错误说明 Redis 可用, 不计入
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var redisErr redis.Error
+	var netErr net.Error
+	if errors.As(err, &redisErr) && !errors.As(err, &netErr) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service/config"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	b := newBreaker("test_0", config.RedisBreaker{Enabled: true, Failures: 2, OpenTimeout: 20, Probes: 1})
+	timeout := context.DeadlineExceeded
+
+	// 连续失败达到阈值后开启
+	for i := 0; i < 2; i++ {
+		ticket, err := b.allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.record(ticket, timeout, 0)
+	}
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("state = %s, want open", got)
+	}
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("熔断期间应拒绝, got %v", err)
+	}
+
+	// 超时后半开, 探测成功则关闭
+	time.Sleep(30 * time.Millisecond)
+	probe, err := b.allow()
+	if err != nil {
+		t.Fatalf("半开应放行探测, got %v", err)
+	}
+	if got := b.currentState(); got != breakerHalfOpen {
+		t.Fatalf("state = %s, want half_open", got)
+	}
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("探测名额用完后应拒绝, got %v", err)
+	}
+	b.record(probe, nil, 0)
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("state = %s, want closed", got)
+	}
+}
+
+func TestBreakerIgnoresCancelledProbe(t *testing.T) {
+	b := newBreaker("test_0", config.RedisBreaker{Enabled: true, Failures: 1, OpenTimeout: 20, Probes: 1})
+	ticket, _ := b.allow()
+	b.record(ticket, context.DeadlineExceeded, 0)
+	time.Sleep(30 * time.Millisecond)
+
+	// 探测被调用方取消, 不计入成功或失败, 归还探测名额
+	probe, err := b.allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.record(probe, context.Canceled, 0)
+	if got := b.currentState(); got != breakerHalfOpen {
+		t.Fatalf("取消的探测不应改变状态, state = %s", got)
+	}
+	probe, err = b.allow()
+	if err != nil {
+		t.Fatalf("取消的探测应归还名额, got %v", err)
+	}
+	b.record(probe, context.DeadlineExceeded, 0)
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("探测失败应重新开启, state = %s", got)
+	}
+}
+
+func TestBreakerCancelledDoesNotResetFailures(t *testing.T) {
+	b := newBreaker("test_0", config.RedisBreaker{Enabled: true, Failures: 2, OpenTimeout: 1000})
+	for _, err := range []error{context.DeadlineExceeded, context.Canceled, context.DeadlineExceeded} {
+		ticket, _ := b.allow()
+		b.record(ticket, err, 0)
+	}
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("取消的命令不应重置连续失败次数, state = %s", got)
+	}
+}
+
+// 状态切换前放行的命令在切换后才返回, 结果不计入当前状态
+func TestBreakerIgnoresStaleResults(t *testing.T) {
+	b := newBreaker("test_0", config.RedisBreaker{Enabled: true, Failures: 1, OpenTimeout: 20, Probes: 1})
+	stale, _ := b.allow() // 关闭期间放行, 很慢才返回
+	ticket, _ := b.allow()
+	b.record(ticket, context.DeadlineExceeded, 0)
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("state = %s, want open", got)
+	}
+
+	// 半开期间返回的旧命令成功不能代替探测关闭熔断
+	time.Sleep(30 * time.Millisecond)
+	probe, err := b.allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.record(stale, nil, 0)
+	if got := b.currentState(); got != breakerHalfOpen {
+		t.Fatalf("旧命令的结果不应计入探测, state = %s", got)
+	}
+	b.record(stale, context.DeadlineExceeded, 0)
+	if got := b.currentState(); got != breakerHalfOpen {
+		t.Fatalf("旧命令的失败不应重新开启, state = %s", got)
+	}
+	b.record(stale, context.Canceled, 0)
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("旧命令取消不应归还探测名额, got %v", err)
+	}
+
+	b.record(probe, nil, 0)
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("state = %s, want closed", got)
+	}
+	// 关闭后旧命令的失败也不计入连续失败次数
+	b.record(stale, context.DeadlineExceeded, 0)
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("旧命令的失败不应开启熔断, state = %s", got)
+	}
+}
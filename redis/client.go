@@ -0,0 +1,267 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"service/config"
+	"service/health"
+	"service/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 初始化策略
+const (
+	InitEager    = "eager"    // 启动时连接, 失败则启动失败
+	InitLazy     = "lazy"     // 首次获取客户端时连接
+	InitOptional = "optional" // 启动时连接, 失败则降级启动并在后台重试
+)
+
+// State 客户端状态
+type State string
+
+const (
+	StateIdle       State = "idle"       // lazy 尚未使用
+	StateConnecting State = "connecting" // 正在连接
+	StateReady      State = "ready"      // 已连接
+	StateDown       State = "down"       // 连接失败, optional 在后台重试, lazy 在退避结束后的下次获取时重连
+)
+
+// 后台重连的退避时间
+const (
+	retryMinBackoff = time.Second
+	retryMaxBackoff = 30 * time.Second
+	connectTimeout  = 5 * time.Second
+)
+
+// entry 单个 实例名_db 的客户端及其状态
+type entry struct {
+	key      string
+	instance config.RedisInstanceConfig
+	db       config.DBConfig
+	dial     dialer
+	breaker  *breaker // 未开启熔断时为 nil
+
+	mu      sync.Mutex    // 串行化连接和关闭
+	client  atomic.Value  // redis.UniversalClient, 连接成功后写入
+	state   atomic.Value  // State
+	err     atomic.Value  // error, 最近一次连接失败的原因
+	retryAt atomic.Int64  // lazy 连接失败后, 在此时间(UnixNano)之前获取客户端直接返回失败原因
+	backoff time.Duration // lazy 下次失败后的等待时间, 由 mu 保护
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newEntry(key string, instance config.RedisInstanceConfig, db config.DBConfig, dial dialer) *entry {
+	e := &entry{key: key, instance: instance, db: db, dial: dial, breaker: newBreaker(key, instance.Breaker), stop: make(chan struct{})}
+	e.state.Store(StateIdle)
+	return e
+}
+
+func (e *entry) policy() string {
+	if e.instance.Init == "" {
+		return InitEager
+	}
+	return e.instance.Init
+}
+
+// connect 创建客户端并 Ping, 已连接时直接返回
+func (e *entry) connect(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.connectLocked(ctx)
+}
+
+// connectLocked 同 connect, 调用方需持有 mu; 已关闭时不再连接, 避免关闭后写入新的客户端
+func (e *entry) connectLocked(ctx context.Context) error {
+	if _, ok := e.loaded(); ok {
+		return nil
+	}
+	if e.stopped() {
+		return fmt.Errorf("Redis %s 已关闭", e.key)
+	}
+
+	e.state.Store(StateConnecting)
+	client := newClient(e.instance, e.db, e.dial)
+	// 熔断放在最外层, 熔断期间的命令不再记录命令日志和链路
+	if e.breaker != nil {
+		client.AddHook(e.breaker)
+	}
+	client.AddHook(logHook{key: e.key})
+	client.AddHook(newTracingHook(endpoint(e.instance), e.db.DB))
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return e.fail(fmt.Errorf("无法连接到 Redis instance %s, db %d: %w", e.instance.Name, e.db.DB, classifyError(err)))
+	}
+	e.client.Store(client)
+	e.state.Store(StateReady)
+	go e.reapIdle(client)
+	return nil
+}
+
+func (e *entry) fail(err error) error {
+	e.err.Store(err)
+	e.state.Store(StateDown)
+	return err
+}
+
+func (e *entry) loaded() (redis.UniversalClient, bool) {
+	client, ok := e.client.Load().(redis.UniversalClient)
+	return client, ok
+}
+
+// get 获取客户端, lazy 在首次获取时连接
+// lazy 连接失败后按指数退避, 等待期间直接返回失败原因, 避免每次获取都排队等待连接超时
+func (e *entry) get() (redis.UniversalClient, error) {
+	if client, ok := e.loaded(); ok {
+		return client, nil
+	}
+	if e.policy() != InitLazy || time.Now().UnixNano() < e.retryAt.Load() {
+		return nil, e.unavailable()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// 等锁期间其他调用方可能已连接成功或失败
+	if client, ok := e.loaded(); ok {
+		return client, nil
+	}
+	if time.Now().UnixNano() < e.retryAt.Load() {
+		return nil, e.unavailable()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := e.connectLocked(ctx); err != nil {
+		e.backoff = min(max(e.backoff*2, retryMinBackoff), retryMaxBackoff)
+		e.retryAt.Store(time.Now().Add(e.backoff).UnixNano())
+		return nil, err
+	}
+	e.backoff = 0
+	client, _ := e.loaded()
+	return client, nil
+}
+
+// unavailable 返回包含最近一次失败原因的错误
+func (e *entry) unavailable() error {
+	err, _ := e.err.Load().(error)
+	return fmt.Errorf("Redis %s 暂不可用: %w", e.key, err)
+}
+
+// retry 后台按指数退避重连, 直到成功或关闭
+func (e *entry) retry() {
+	backoff := retryMinBackoff
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-time.After(backoff):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		err := e.connect(ctx)
+		cancel()
+		if err == nil {
+			logger.Info(context.Background(), "Redis 重连成功", zap.String("client", e.key))
+			return
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+		logger.Warn(context.Background(), "Redis 重连失败",
+			zap.String("client", e.key),
+			zap.Duration("next", backoff),
+			zap.Error(err),
+		)
+	}
+}
+
+// reapIdle 每隔半个空闲超时取用一次连接, 直到关闭
+// go-redis 只在取用连接时检查空闲超时, 没有后台回收, 突发创建的连接不再使用时会一直保留
+func (e *entry) reapIdle(client redis.UniversalClient) {
+	idle := seconds(e.db.ConnMaxIdleTime)
+	if idle == 0 {
+		idle = defaultConnMaxIdle
+	}
+	if idle < 0 {
+		return
+	}
+	ticker := time.NewTicker(idle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		switch c := client.(type) {
+		case *redis.ClusterClient:
+			_ = c.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+				reapPool(ctx, shard)
+				return nil
+			})
+		case *redis.Client:
+			reapPool(ctx, c)
+		}
+		cancel()
+	}
+}
+
+// reapPool 空闲连接多于保留数量时取用一个连接再归还, 取用时连接池关闭排在前面的空闲超时连接
+// 通过 Conn 发送 Ping, 不经过 hook, 不计入熔断, 也不记录命令日志和链路
+func reapPool(ctx context.Context, client *redis.Client) {
+	if int(client.PoolStats().IdleConns) <= max(client.Options().MinIdleConns, 1) {
+		return
+	}
+	conn := client.Conn()
+	_ = conn.Ping(ctx).Err()
+	_ = conn.Close()
+}
+
+// close 停止后台重连并关闭客户端, 等待进行中的连接结束, 之后不再创建新的客户端
+func (e *entry) close() error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if client, ok := e.loaded(); ok {
+		return client.Close()
+	}
+	return nil
+}
+
+func (e *entry) stopped() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// State 当前状态, 用于就绪检查
+func (e *entry) State() string {
+	return string(e.state.Load().(State))
+}
+
+// Check 就绪检查: lazy 未使用时不影响就绪, 连接失败时为降级, 下次获取客户端时重连; optional 不可用时为降级
+func (e *entry) Check(ctx context.Context) error {
+	var err error
+	client, loaded := e.loaded()
+	if loaded {
+		err = client.Ping(ctx).Err()
+	} else if e.state.Load().(State) != StateIdle {
+		err, _ = e.err.Load().(error)
+		if err == nil {
+			err = errors.New("正在连接")
+		}
+	}
+	if err != nil && (e.policy() == InitOptional || e.policy() == InitLazy && !loaded) {
+		return health.Degraded(err)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"service/config"
+	"service/health"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestEntry 创建指向 miniredis 的客户端, 测试结束时关闭
+func newTestEntry(t *testing.T, mr *miniredis.Miniredis, init string) *entry {
+	t.Helper()
+	host, port, _ := strings.Cut(mr.Addr(), ":")
+	p, _ := strconv.Atoi(port)
+	instance := config.RedisInstanceConfig{Name: "test", Init: init, Addr: host, Port: p}
+	dial, err := newDialer(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEntry(fmt.Sprintf("%s_%d", t.Name(), 0), instance, config.DBConfig{DialTimeout: 200}, dial)
+	t.Cleanup(func() { _ = e.close() })
+	return e
+}
+
+func TestLazyBackoffAfterFailure(t *testing.T) {
+	mr := miniredis.RunT(t)
+	e := newTestEntry(t, mr, InitLazy)
+	mr.Close()
+
+	if _, err := e.get(); !errors.Is(err, ErrDial) {
+		t.Fatalf("连接失败应返回 ErrDial, got %v", err)
+	}
+
+	// 退避期间直接返回失败原因, 不再排队连接
+	start := time.Now()
+	_, err := e.get()
+	if !errors.Is(err, ErrDial) {
+		t.Fatalf("退避期间应返回最近一次失败原因, got %v", err)
+	}
+	if cost := time.Since(start); cost > 50*time.Millisecond {
+		t.Fatalf("退避期间获取客户端耗时 %s", cost)
+	}
+
+	// 连接失败的 lazy 客户端为降级, 不影响就绪
+	name := "redis:" + e.key
+	health.Register(name, e)
+	defer health.Unregister(name)
+	if _, report := health.Ready(context.Background()); report.Checks[name].Status != "degraded" {
+		t.Fatalf("status = %s, want degraded", report.Checks[name].Status)
+	}
+
+	// 退避结束后重新连接
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	e.retryAt.Store(0)
+	client, err := e.get()
+	if err != nil {
+		t.Fatalf("退避结束后应重新连接, got %v", err)
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, report := health.Ready(context.Background()); report.Checks[name].Status != "up" {
+		t.Fatalf("status = %s, want up", report.Checks[name].Status)
+	}
+}
+
+func TestConnectAfterClose(t *testing.T) {
+	mr := miniredis.RunT(t)
+	e := newTestEntry(t, mr, InitOptional)
+	if err := e.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.connect(context.Background()); err == nil {
+		t.Fatal("关闭后不应再连接")
+	}
+	if _, ok := e.loaded(); ok {
+		t.Fatal("关闭后不应写入新的客户端")
+	}
+}
+
+func TestCloseWaitsForConnect(t *testing.T) {
+	mr := miniredis.RunT(t)
+	e := newTestEntry(t, mr, InitOptional)
+
+	// 模拟后台重连正在进行时开始关闭
+	e.mu.Lock()
+	closed := make(chan error, 1)
+	go func() { closed <- e.close() }()
+	time.Sleep(10 * time.Millisecond)
+	err := e.connectLocked(context.Background())
+	e.mu.Unlock()
+	if err == nil {
+		t.Fatal("关闭开始后不应再连接")
+	}
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := e.loaded(); ok {
+		t.Fatal("关闭后不应写入新的客户端")
+	}
+}
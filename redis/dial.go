@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"service/config"
+	"strings"
+	"time"
+)
+
+// 连接失败的阶段, 可通过 errors.Is 判断
+var (
+	ErrDial      = errors.New("Redis 连接失败")
+	ErrHandshake = errors.New("Redis TLS 握手失败")
+	ErrAuth      = errors.New("Redis 认证失败")
+)
+
+// dialer 拨号函数, 同一实例的各个 db 共用, 证书只加载一次
+type dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// withTimeout 为单个 db 的连接池附加连接超时, 自定义 Dialer 会使 go-redis 忽略 DialTimeout
+func (d dialer) withTimeout(timeout time.Duration) dialer {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return d(ctx, network, addr)
+	}
+}
+
+// newDialer 创建拨号函数, 开启 TLS 时在 TCP 连接建立后完成握手
+// 自定义 Dialer 会使 go-redis 忽略 TLSConfig, 因此 TLS 在这里处理
+func newDialer(instance config.RedisInstanceConfig) (dialer, error) {
+	tlsConfig, err := newTLSConfig(instance.TLS)
+	if err != nil {
+		return nil, err
+	}
+	netDialer := &net.Dialer{KeepAlive: 5 * time.Minute}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := netDialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDial, err)
+		}
+		if tlsConfig == nil {
+			return conn, nil
+		}
+
+		cfg := tlsConfig
+		// 未指定服务端名称时按连接地址校验, 集群和哨兵模式下每个节点地址不同
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w: %s: %w", ErrHandshake, addr, err)
+		}
+		return tlsConn, nil
+	}, nil
+}
+
+// newTLSConfig 按配置加载证书, 未开启 TLS 时返回 nil
+func newTLSConfig(cfg config.RedisTLS) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书 %s 中没有有效的证书", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// classifyError 区分认证失败, 连接和握手失败已由拨号函数标记
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrDial) || errors.Is(err, ErrHandshake) {
+		return err
+	}
+	msg := err.Error()
+	for _, prefix := range []string{"WRONGPASS", "NOAUTH", "NOPERM", "ERR AUTH", "ERR invalid password", "ERR Client sent AUTH"} {
+		if strings.HasPrefix(msg, prefix) {
+			return fmt.Errorf("%w: %w", ErrAuth, err)
+		}
+	}
+	return err
+}
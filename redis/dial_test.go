@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"service/config"
+)
+
+// connectAddr 按地址创建客户端并连接一次, 返回连接错误
+func connectAddr(t *testing.T, addr string, tlsConfig config.RedisTLS) error {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(addr)
+	p, _ := strconv.Atoi(port)
+	instance := config.RedisInstanceConfig{Name: "test", Init: InitLazy, Addr: host, Port: p, TLS: tlsConfig}
+	dial, err := newDialer(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEntry(fmt.Sprintf("%s_%d", t.Name(), 0), instance, config.DBConfig{DialTimeout: 200}, dial)
+	t.Cleanup(func() { _ = e.close() })
+	return e.connect(context.Background())
+}
+
+func TestConnectUntrustedCertificate(t *testing.T) {
+	// httptest 的自签名证书不在系统信任的 CA 中
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // 忽略服务端的握手失败日志
+	srv.StartTLS()
+	defer srv.Close()
+
+	err := connectAddr(t, srv.Listener.Addr().String(), config.RedisTLS{Enabled: true})
+	if !errors.Is(err, ErrHandshake) {
+		t.Fatalf("证书不受信任应返回 ErrHandshake, got %v", err)
+	}
+	if errors.Is(err, ErrDial) || errors.Is(err, ErrAuth) {
+		t.Fatalf("握手失败不应标记为其他阶段, got %v", err)
+	}
+}
+
+func TestConnectClosedPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	err = connectAddr(t, addr, config.RedisTLS{})
+	if !errors.Is(err, ErrDial) {
+		t.Fatalf("端口未监听应返回 ErrDial, got %v", err)
+	}
+	if errors.Is(err, ErrHandshake) || errors.Is(err, ErrAuth) {
+		t.Fatalf("连接失败不应标记为其他阶段, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"service/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// logHook 记录 Redis 命令日志, 日志中带有调用方上下文的链路ID
+// 运行期间的认证失败(如密码轮换后新建连接)同样标记为 ErrAuth, 调用方可通过 errors.Is 判断
+type logHook struct {
+	key string
+}
+
+func (h logHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			logger.Warn(ctx, "Redis 连接失败", zap.String("client", h.key), zap.Error(err))
+		}
+		return conn, err
+	}
+}
+
+func (h logHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := classify(cmd, next(ctx, cmd))
+		h.log(ctx, cmd.Name(), 1, time.Since(start), err)
+		return err
+	}
+}
+
+func (h logHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := classifyError(next(ctx, cmds))
+		for _, cmd := range cmds {
+			classify(cmd, cmd.Err())
+		}
+		h.log(ctx, "pipeline", len(cmds), time.Since(start), err)
+		return err
+	}
+}
+
+func (h logHook) log(ctx context.Context, name string, count int, cost time.Duration, err error) {
+	fields := []zap.Field{
+		zap.String("client", h.key),
+		zap.String("cmd", name),
+		zap.Int("count", count),
+		zap.Duration("cost", cost),
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logger.Warn(ctx, "Redis 命令失败", append(fields, zap.Error(err))...)
+		return
+	}
+	logger.Debug(ctx, "Redis 命令", fields...)
+}
+
+// classify 标记命令的错误类型并写回命令, 使 cmd.Err() 与返回的错误一致
+func classify(cmd redis.Cmder, err error) error {
+	if classified := classifyError(err); classified != err {
+		cmd.SetErr(classified)
+		return classified
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLogHookClassifiesAuthError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireAuth("secret")
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), Password: "wrong", MaxRetries: -1})
+	defer client.Close()
+	client.AddHook(logHook{key: "test_0"})
+	ctx := context.Background()
+
+	cmd := client.Get(ctx, "key")
+	if !errors.Is(cmd.Err(), ErrAuth) {
+		t.Fatalf("命令错误应标记为 ErrAuth, got %v", cmd.Err())
+	}
+
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		return nil
+	})
+	if !errors.Is(err, ErrAuth) {
+		t.Fatalf("pipeline 错误应标记为 ErrAuth, got %v", err)
+	}
+
+	// redis.Nil 等其他错误保持原样
+	mr.RequireAuth("")
+	ok := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer ok.Close()
+	ok.AddHook(logHook{key: "test_0"})
+	if err := ok.Get(ctx, "missing").Err(); err != redis.Nil {
+		t.Fatalf("got %v, want redis.Nil", err)
+	}
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"service/config"
+	"service/logger"
+	"service/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testConfig = `
+debug: release
+port: 8080
+limit: 10
+auth:
+  clientId: test
+zap:
+  level: warn
+  sinks:
+    - type: stderr
+tracing:
+  enabled: true
+  exporter: memory
+`
+
+// spanExporter 测试使用的内存导出器
+var spanExporter = tracetest.NewInMemoryExporter()
+
+// TestMain 初始化配置、日志和链路追踪, 这些模块在进程内只能初始化一次
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "redis-test")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	code := 1
+	if err := setup(dir); err != nil {
+		fmt.Println(err)
+	} else {
+		code = m.Run()
+	}
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func setup(dir string) error {
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte(testConfig), 0o644); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.BindFlags(fs)
+	if err := fs.Parse([]string{"-config", file}); err != nil {
+		return err
+	}
+	if err := config.InitConfig(); err != nil {
+		return err
+	}
+	if err := logger.InitLogger(); err != nil {
+		return err
+	}
+	tracing.RegisterExporter("memory", func(config.Tracing) (sdktrace.SpanExporter, error) {
+		return spanExporter, nil
+	})
+	return tracing.InitTracing()
+}
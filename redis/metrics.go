@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"service/metrics"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var registerMetricsOnce sync.Once
+
+var (
+	poolHits = prometheus.NewDesc("service_redis_pool_hits_total",
+		"连接池命中空闲连接的次数", []string{"client"}, nil)
+	poolMisses = prometheus.NewDesc("service_redis_pool_misses_total",
+		"连接池未命中空闲连接的次数", []string{"client"}, nil)
+	poolTimeouts = prometheus.NewDesc("service_redis_pool_timeouts_total",
+		"等待连接超时的次数", []string{"client"}, nil)
+	poolTotalConns = prometheus.NewDesc("service_redis_pool_total_conns",
+		"连接池中的连接数", []string{"client"}, nil)
+	poolIdleConns = prometheus.NewDesc("service_redis_pool_idle_conns",
+		"连接池中的空闲连接数", []string{"client"}, nil)
+	poolStaleConns = prometheus.NewDesc("service_redis_pool_stale_conns_total",
+		"连接池移除的过期连接数", []string{"client"}, nil)
+	breakerStateDesc = prometheus.NewDesc("service_redis_breaker_state",
+		"熔断状态 0 关闭、1 开启、2 半开", []string{"client"}, nil)
+	breakerRejected = prometheus.NewDesc("service_redis_breaker_rejected_total",
+		"熔断拒绝的命令数", []string{"client"}, nil)
+)
+
+// poolCollector 采集 redisClients 中每个客户端的连接池状态, 标签 client 为 实例名_db
+type poolCollector struct{}
+
+func (poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolHits
+	ch <- poolMisses
+	ch <- poolTimeouts
+	ch <- poolTotalConns
+	ch <- poolIdleConns
+	ch <- poolStaleConns
+	ch <- breakerStateDesc
+	ch <- breakerRejected
+}
+
+func (poolCollector) Collect(ch chan<- prometheus.Metric) {
+	redisClients.Range(func(key, value any) bool {
+		e := value.(*entry)
+		name := key.(string)
+		if e.breaker != nil {
+			ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, float64(e.breaker.currentState()), name)
+			ch <- prometheus.MustNewConstMetric(breakerRejected, prometheus.CounterValue, float64(e.breaker.rejected.Load()), name)
+		}
+		// 未连接的客户端没有连接池
+		client, ok := e.loaded()
+		if !ok {
+			return true
+		}
+		stats := client.PoolStats()
+		ch <- prometheus.MustNewConstMetric(poolHits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(poolMisses, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(poolTimeouts, prometheus.CounterValue, float64(stats.Timeouts), name)
+		ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(poolStaleConns, prometheus.CounterValue, float64(stats.StaleConns), name)
+		return true
+	})
+}
+
+// registerMetrics 注册连接池指标
+func registerMetrics() error {
+	var err error
+	registerMetricsOnce.Do(func() {
+		err = metrics.Register(poolCollector{})
+	})
+	return err
+}
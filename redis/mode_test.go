@@ -0,0 +1,192 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"service/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lookPath 查找可执行文件, 不存在时跳过测试
+func lookPath(t *testing.T, name string) string {
+	t.Helper()
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("未找到 %s, 跳过", name)
+	}
+	return path
+}
+
+// freePort 获取一个空闲端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// startServer 启动 redis-server 或 redis-sentinel, lines 为附加的配置行, 等待可以 PING 后返回端口, 测试结束时停止
+func startServer(t *testing.T, bin string, lines ...string) int {
+	t.Helper()
+	port := freePort(t)
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "redis.conf")
+	content := fmt.Sprintf("port %d\nbind 127.0.0.1\ndir %s\n", port, dir)
+	for _, line := range lines {
+		content += line + "\n"
+	}
+	if err := os.WriteFile(conf, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(bin, conf)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:" + strconv.Itoa(port)})
+	defer client.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for client.Ping(context.Background()).Err() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s 启动超时", bin)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return port
+}
+
+// connectEntry 按实例配置创建并连接客户端, 测试结束时关闭
+func connectEntry(t *testing.T, instance config.RedisInstanceConfig, db config.DBConfig) *entry {
+	t.Helper()
+	dial, err := newDialer(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newEntry(fmt.Sprintf("%s_%d", instance.Name, db.DB), instance, db, dial)
+	t.Cleanup(func() { _ = e.close() })
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := e.connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// roundTrip 写入并读回一个键
+func roundTrip(t *testing.T, client redis.UniversalClient) {
+	t.Helper()
+	ctx := context.Background()
+	if err := client.Set(ctx, "mode-test", "ok", time.Minute).Err(); err != nil {
+		t.Fatalf("SET: %v", err)
+	}
+	got, err := client.Get(ctx, "mode-test").Result()
+	if err != nil || got != "ok" {
+		t.Fatalf("GET = %q, %v", got, err)
+	}
+}
+
+func TestStandalone(t *testing.T) {
+	port := startServer(t, lookPath(t, "redis-server"))
+	e := connectEntry(t, config.RedisInstanceConfig{Name: "standalone", Addr: "127.0.0.1", Port: port}, config.DBConfig{DB: 1})
+
+	client, err := e.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*redis.Client); !ok {
+		t.Fatalf("standalone 应为 *redis.Client, got %T", client)
+	}
+	roundTrip(t, client)
+	if got := e.State(); got != string(StateReady) {
+		t.Errorf("State = %s", got)
+	}
+}
+
+func TestSentinel(t *testing.T) {
+	server := lookPath(t, "redis-server")
+	sentinel := lookPath(t, "redis-sentinel")
+	masterPort := startServer(t, server)
+	sentinelPort := startServer(t, sentinel, fmt.Sprintf("sentinel monitor mymaster 127.0.0.1 %d 1", masterPort))
+
+	e := connectEntry(t, config.RedisInstanceConfig{
+		Name:          "sentinel",
+		Mode:          "sentinel",
+		MasterName:    "mymaster",
+		SentinelAddrs: []string{"127.0.0.1:" + strconv.Itoa(sentinelPort)},
+	}, config.DBConfig{DB: 2})
+
+	client, err := e.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*redis.Client); !ok {
+		t.Fatalf("sentinel 应为 *redis.Client, got %T", client)
+	}
+	roundTrip(t, client)
+
+	// 写入的是哨兵发现的主节点
+	master := redis.NewClient(&redis.Options{Addr: "127.0.0.1:" + strconv.Itoa(masterPort), DB: 2})
+	defer master.Close()
+	if got, err := master.Get(context.Background(), "mode-test").Result(); err != nil || got != "ok" {
+		t.Fatalf("主节点 GET = %q, %v", got, err)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	port := startServer(t, lookPath(t, "redis-server"), "cluster-enabled yes", "cluster-config-file nodes.conf")
+
+	// 单节点集群, 分配全部槽位
+	node := redis.NewClient(&redis.Options{Addr: "127.0.0.1:" + strconv.Itoa(port)})
+	defer node.Close()
+	ctx := context.Background()
+	slots := make([]int, 16384)
+	for i := range slots {
+		slots[i] = i
+	}
+	if err := node.ClusterAddSlots(ctx, slots...).Err(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, _ := node.ClusterInfo(ctx).Result()
+		if strings.Contains(info, "cluster_state:ok") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("集群状态未就绪")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	e := connectEntry(t, config.RedisInstanceConfig{
+		Name:         "cluster",
+		Mode:         "cluster",
+		ClusterAddrs: []string{"127.0.0.1:" + strconv.Itoa(port)},
+	}, config.DBConfig{DB: 0})
+
+	client, err := e.get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*redis.ClusterClient); !ok {
+		t.Fatalf("cluster 应为 *redis.ClusterClient, got %T", client)
+	}
+	roundTrip(t, client)
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"service/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/spf13/viper"
+)
+
+// sampleDBs 读取示例配置 config.yaml 中第一个实例的 db 列表
+func sampleDBs(tb testing.TB) []config.DBConfig {
+	tb.Helper()
+	v := viper.New()
+	v.SetConfigFile("../config.yaml")
+	if err := v.ReadInConfig(); err != nil {
+		tb.Fatal(err)
+	}
+	var instances []config.RedisInstanceConfig
+	if err := v.UnmarshalKey("redis.instances", &instances); err != nil {
+		tb.Fatal(err)
+	}
+	if len(instances) == 0 || len(instances[0].DBs) == 0 {
+		tb.Fatal("示例配置中没有 Redis db")
+	}
+	return instances[0].DBs
+}
+
+// connectSample 按示例配置的 db 列表连接 miniredis, 空闲超时缩短为1秒, 返回各 db 的客户端和统计总连接数的函数
+func connectSample(tb testing.TB) ([]*entry, func() int) {
+	tb.Helper()
+	dbs := sampleDBs(tb)
+	mr := miniredis.RunT(tb)
+	host, port, _ := strings.Cut(mr.Addr(), ":")
+	p, _ := strconv.Atoi(port)
+	instance := config.RedisInstanceConfig{Name: "sample", Addr: host, Port: p}
+	dial, err := newDialer(instance)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	entries := make([]*entry, len(dbs))
+	for i, db := range dbs {
+		db.ConnMaxIdleTime = 1 // 缩短空闲超时, 其余参数沿用示例配置
+		entries[i] = newEntry(fmt.Sprintf("sample_%d", db.DB), instance, db, dial)
+		tb.Cleanup(func() { _ = entries[i].close() })
+		if err := entries[i].connect(context.Background()); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	total := func() int {
+		n := 0
+		for _, e := range entries {
+			client, _ := e.loaded()
+			n += int(client.PoolStats().TotalConns)
+		}
+		return n
+	}
+	return entries, total
+}
+
+// burst 每个 db 并发发送 n 个命令, 使连接池创建多个连接
+func burst(entries []*entry, n int) {
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		client, _ := e.loaded()
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = client.Do(context.Background(), "PING").Err()
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// waitConns 等待后台回收空闲连接, 总连接数降到 want 或超时后返回当前连接数
+func waitConns(total func() int, want int, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if n := total(); n <= want {
+			return n
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return total()
+}
+
+// TestPoolConnsSampleConfig 记录示例配置下各 db 连接池的连接数:
+// 启动后每个 db 只保留 Ping 使用的 1 个连接, 没有新的请求时突发创建的连接在空闲超时后由后台回收到 1 个
+func TestPoolConnsSampleConfig(t *testing.T) {
+	entries, total := connectSample(t)
+
+	after := total()
+	t.Logf("%d 个 db 启动后连接数: %d", len(entries), after)
+	if after != len(entries) {
+		t.Errorf("启动后连接数 = %d, want %d", after, len(entries))
+	}
+
+	burst(entries, 5)
+	busy := total()
+	t.Logf("并发请求后连接数: %d", busy)
+	if busy <= len(entries) {
+		t.Fatalf("并发请求后连接数 = %d, 应多于 %d", busy, len(entries))
+	}
+
+	// 之后不再发送命令, 只靠后台回收
+	idle := waitConns(total, len(entries), 5*time.Second)
+	t.Logf("空闲超时后连接数: %d", idle)
+	if idle != len(entries) {
+		t.Errorf("空闲超时后连接数 = %d, want %d", idle, len(entries))
+	}
+}
+
+// BenchmarkPoolConnsSampleConfig 报告示例配置下所有 db 在并发请求后和空闲回收后的总连接数
+func BenchmarkPoolConnsSampleConfig(b *testing.B) {
+	entries, total := connectSample(b)
+	b.SetParallelism(8) // 每个 CPU 8 个并发, 使连接池创建多个连接
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			client, _ := entries[i%len(entries)].loaded()
+			_ = client.Do(context.Background(), "PING").Err()
+			i++
+		}
+	})
+	b.StopTimer()
+	b.ReportMetric(float64(total()), "busy-conns")
+	b.ReportMetric(float64(waitConns(total, len(entries), 5*time.Second)), "idle-conns")
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"service/config"
+	"service/health"
 	"service/logger"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,57 +16,154 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// 实例名_db -> *entry
 var redisClients sync.Map
 
+// InitRedis 按实例的初始化策略创建客户端, 只有 eager 的连接失败会中断启动
 func InitRedis() error {
-	for _, instance := range config.AppConfig.Redis.Instances {
+	if err := registerMetrics(); err != nil {
+		return fmt.Errorf("注册 Redis 指标失败: %w", err)
+	}
+	for _, instance := range config.Get().Redis.Instances {
+		// 同一实例的各个 db 共用拨号函数
+		dial, err := newDialer(instance)
+		if err != nil {
+			return fmt.Errorf("Redis instance %s 配置错误: %w", instance.Name, err)
+		}
 		for _, db := range instance.DBs {
-			client := redis.NewClient(&redis.Options{
-				Addr:         fmt.Sprintf("%s:%d", instance.Addr, instance.Port),
-				Password:     instance.Password,
-				DB:           db.DB,
-				PoolSize:     db.PoolSize,
-				MinIdleConns: 10,
-
-				DialTimeout:  10 * time.Second,
-				ReadTimeout:  10 * time.Second,
-				WriteTimeout: 10 * time.Second,
-				MaxRetries:   5,
-
-				Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
-					netDialer := &net.Dialer{
-						Timeout:   5 * time.Second,
-						KeepAlive: 5 * time.Minute,
-					}
-					return netDialer.DialContext(ctx, network, addr)
-				},
-			})
-			if err := client.Ping(context.Background()).Err(); err != nil {
-				return fmt.Errorf("无法连接到 Redis instance %s, db %d: %w", instance.Name, db.DB, err)
-			}
 			key := fmt.Sprintf("%s_%d", instance.Name, db.DB)
-			redisClients.Store(key, client)
+			e := newEntry(key, instance, db, dial)
+			switch e.policy() {
+			case InitLazy:
+			case InitOptional:
+				ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+				err := e.connect(ctx)
+				cancel()
+				if err != nil {
+					logger.Warn(context.Background(), "Redis 连接失败, 降级启动并在后台重试", zap.String("client", key), zap.Error(err))
+					go e.retry()
+				}
+			default:
+				if err := e.connect(context.Background()); err != nil {
+					return err
+				}
+			}
+			redisClients.Store(key, e)
+			health.Register("redis:"+key, e)
 		}
 	}
 	return nil
 }
 
-func GetRedisClient(name string, db int) (*redis.Client, error) {
+// 连接池参数的默认值, 配置为0时使用
+const (
+	defaultDialTimeout  = 5 * time.Second
+	defaultReadTimeout  = 10 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+	defaultMaxRetries   = 5
+	defaultConnMaxIdle  = 30 * time.Minute // 与 go-redis 一致
+)
+
+// newClient 按实例模式创建客户端: standalone 单机, sentinel 通过哨兵连接主节点, cluster 集群
+// 每个 db 一个连接池, 默认不保留最小空闲连接, 空闲超时的连接由 reapIdle 回收, 空闲的 db 最终保留1个连接
+// 连接池使用 FIFO, 取用时从最早归还的连接开始检查空闲超时
+func newClient(instance config.RedisInstanceConfig, db config.DBConfig, dial dialer) redis.UniversalClient {
+	dialTimeout := millis(db.DialTimeout, defaultDialTimeout)
+	opts := &redis.UniversalOptions{
+		Username:         instance.Username,
+		Password:         instance.Password.Value(),
+		MasterName:       instance.MasterName,
+		SentinelPassword: instance.SentinelPassword.Value(),
+		DB:               db.DB,
+		PoolSize:         db.PoolSize,
+		MinIdleConns:     db.MinIdleConns,
+		MaxIdleConns:     db.MaxIdleConns,
+		ConnMaxIdleTime:  seconds(db.ConnMaxIdleTime),
+		PoolFIFO:         true,
+
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  millis(db.ReadTimeout, defaultReadTimeout),
+		WriteTimeout: millis(db.WriteTimeout, defaultWriteTimeout),
+		MaxRetries:   db.MaxRetries,
+
+		Dialer: dial.withTimeout(dialTimeout),
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	switch instance.Mode {
+	case "sentinel":
+		opts.Addrs = instance.SentinelAddrs
+		return redis.NewFailoverClient(opts.Failover())
+	case "cluster":
+		opts.Addrs = instance.ClusterAddrs
+		return redis.NewClusterClient(opts.Cluster())
+	default:
+		opts.Addrs = []string{fmt.Sprintf("%s:%d", instance.Addr, instance.Port)}
+		return redis.NewClient(opts.Simple())
+	}
+}
+
+// millis 毫秒配置转换为时长, 为0时使用默认值, -1 原样传给 go-redis 表示不超时
+func millis(value int, fallback time.Duration) time.Duration {
+	switch {
+	case value == 0:
+		return fallback
+	case value < 0:
+		return -1
+	}
+	return time.Duration(value) * time.Millisecond
+}
+
+// seconds 秒配置转换为时长, 0 和 -1 原样传给 go-redis
+func seconds(value int) time.Duration {
+	if value < 0 {
+		return -1
+	}
+	return time.Duration(value) * time.Second
+}
+
+// endpoint 实例地址, 用于日志和链路追踪
+func endpoint(instance config.RedisInstanceConfig) string {
+	switch instance.Mode {
+	case "sentinel":
+		return instance.MasterName
+	case "cluster":
+		return strings.Join(instance.ClusterAddrs, ",")
+	default:
+		return fmt.Sprintf("%s:%d", instance.Addr, instance.Port)
+	}
+}
+
+// GetUniversalClient 获取客户端, 适用于全部模式; lazy 在首次获取时连接, optional 未连接成功时返回错误
+func GetUniversalClient(name string, db int) (redis.UniversalClient, error) {
 	key := fmt.Sprintf("%s_%d", name, db)
-	if client, ok := redisClients.Load(key); ok {
-		return client.(*redis.Client), nil
+	if e, ok := redisClients.Load(key); ok {
+		return e.(*entry).get()
 	}
 	return nil, errors.New(fmt.Sprintf("未配置 Redis %s and db %d", name, db))
 }
 
+// GetRedisClient 获取 standalone 或 sentinel 模式的客户端, cluster 模式请使用 GetUniversalClient
+func GetRedisClient(name string, db int) (*redis.Client, error) {
+	client, err := GetUniversalClient(name, db)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := client.(*redis.Client); ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("Redis %s 为 cluster 模式, 请使用 GetUniversalClient", name)
+}
+
 func Close(ctx context.Context) {
 	var wg sync.WaitGroup
 	redisClients.Range(func(key, value interface{}) bool {
-		if client, ok := value.(*redis.Client); ok {
+		if e, ok := value.(*entry); ok {
 			wg.Add(1)
-			go func(key interface{}, client *redis.Client) {
+			go func(key interface{}, e *entry) {
 				defer wg.Done()
-				if err := client.Close(); err != nil {
+				if err := e.close(); err != nil {
 					logger.Error(ctx,
 						"关闭失败 Redis client",
 						zap.Any("key", key),
@@ -75,7 +173,7 @@ func Close(ctx context.Context) {
 						"关闭成功 Redis client",
 						zap.Any("key", key))
 				}
-			}(key, client)
+			}(key, e)
 		}
 		return true
 	})
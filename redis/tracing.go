@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"service/tracing"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook 为每条 Redis 命令创建子 span, 未开启链路追踪时直接放行
+type tracingHook struct {
+	attrs []attribute.KeyValue
+}
+
+func newTracingHook(addr string, db int) tracingHook {
+	return tracingHook{attrs: []attribute.KeyValue{
+		semconv.DBSystemRedis,
+		semconv.ServerAddressKey.String(addr),
+		semconv.DBNamespaceKey.String(strconv.Itoa(db)),
+	}}
+}
+
+func (h tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !tracing.Enabled() {
+			return next(ctx, network, addr)
+		}
+		ctx, span := h.start(ctx, "redis.dial")
+		defer span.End()
+		conn, err := next(ctx, network, addr)
+		h.end(span, err)
+		return conn, err
+	}
+}
+
+func (h tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !tracing.Enabled() {
+			return next(ctx, cmd)
+		}
+		ctx, span := h.start(ctx, "redis "+cmd.Name(), semconv.DBOperationNameKey.String(cmd.Name()))
+		defer span.End()
+		err := next(ctx, cmd)
+		h.end(span, err)
+		return err
+	}
+}
+
+func (h tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !tracing.Enabled() {
+			return next(ctx, cmds)
+		}
+		ctx, span := h.start(ctx, "redis pipeline", attribute.Int("db.redis.pipeline_length", len(cmds)))
+		defer span.End()
+		err := next(ctx, cmds)
+		h.end(span, err)
+		return err
+	}
+}
+
+func (h tracingHook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(h.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (h tracingHook) end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"service/middleware"
+	"service/reqctx"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestServerSpanParentsRedisSpan(t *testing.T) {
+	spanExporter.Reset()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	client.AddHook(newTracingHook(mr.Addr(), 0))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(middleware.Trace(), middleware.Tracing())
+	var traceID, spanID string
+	r.GET("/ping", func(c *gin.Context) {
+		traceID, spanID = reqctx.TraceID(c), reqctx.SpanID(c)
+		_ = client.Get(c, "key").Err()
+		c.Status(http.StatusOK)
+	})
+
+	const (
+		upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		upstreamSpanID  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("traceparent", "00-"+upstreamTraceID+"-"+upstreamSpanID+"-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if err := otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var server, command *tracetest.SpanStub
+	spans := spanExporter.GetSpans()
+	for i := range spans {
+		switch spans[i].SpanKind {
+		case trace.SpanKindServer:
+			server = &spans[i]
+		case trace.SpanKindClient:
+			if strings.HasPrefix(spans[i].Name, "redis get") {
+				command = &spans[i]
+			}
+		}
+	}
+	if server == nil || command == nil {
+		t.Fatalf("缺少 span: %+v", spans)
+	}
+
+	// 服务端 span 沿用 reqctx 中的链路ID和 span ID, 父 span 为上游
+	if traceID != upstreamTraceID {
+		t.Errorf("reqctx trace id = %s", traceID)
+	}
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("server trace id = %s, want %s", got, traceID)
+	}
+	if got := server.SpanContext.SpanID().String(); got != spanID {
+		t.Errorf("server span id = %s, want %s", got, spanID)
+	}
+	if got := server.Parent.SpanID().String(); got != upstreamSpanID {
+		t.Errorf("server parent = %s, want %s", got, upstreamSpanID)
+	}
+	if got := w.Header().Get("traceparent"); got != "00-"+traceID+"-"+spanID+"-01" {
+		t.Errorf("traceparent = %s", got)
+	}
+
+	// Redis 命令 span 挂在服务端 span 下
+	if got := command.SpanContext.TraceID(); got != server.SpanContext.TraceID() {
+		t.Errorf("redis trace id = %s", got)
+	}
+	if got := command.Parent.SpanID(); got != server.SpanContext.SpanID() {
+		t.Errorf("redis parent = %s, want %s", got, server.SpanContext.SpanID())
+	}
+	if got := command.SpanContext.SpanID(); got == server.SpanContext.SpanID() {
+		t.Error("redis span 不应复用服务端 span ID")
+	}
+}
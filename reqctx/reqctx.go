@@ -0,0 +1,193 @@
+package reqctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// valuesKey 请求上下文中 values 的键
+type valuesKey struct{}
+
+// ginValuesKey gin 上下文中记录本请求当前 values 的键
+const ginValuesKey = "reqctx.values"
+
+// values 请求上下文中的值, 放入上下文后只读, 写入时复制
+type values struct {
+	traceID      string
+	spanID       string
+	parentSpanID string
+	traceFlags   string
+	traceState   string
+	clientID     string
+	startTime    time.Time
+	extra        map[any]any // WithValue 写入的其他值
+}
+
+// load 读取上下文中的 values, 不存在时返回 nil
+func load(ctx context.Context) *values {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return nil
+		}
+		ctx = c.Request.Context()
+	}
+	v, _ := ctx.Value(valuesKey{}).(*values)
+	return v
+}
+
+// clone 复制 values, v 为 nil 时返回空值
+func (v *values) clone() *values {
+	if v == nil {
+		return &values{}
+	}
+	c := *v
+	if v.extra != nil {
+		c.extra = make(map[any]any, len(v.extra))
+		for key, val := range v.extra {
+			c.extra[key] = val
+		}
+	}
+	return &c
+}
+
+// update 修改请求上下文中的值, 每次写入都复制一份新的 values, 已放入上下文的 values 不再修改,
+// 之前派生出的上下文(如交给其他协程的)读取时不会与本次写入竞争
+// 传入 *gin.Context 时替换其 Request 的上下文并返回原 gin 上下文, 转换为 context.Context 后仍可读取;
+// 其他上下文写入新的上下文, 不影响父上下文
+func update(ctx context.Context, fn func(v *values)) context.Context {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return c
+		}
+		v := load(c).clone()
+		fn(v)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), valuesKey{}, v))
+		c.Set(ginValuesKey, v)
+		return c
+	}
+	v := load(ctx).clone()
+	fn(v)
+	return context.WithValue(ctx, valuesKey{}, v)
+}
+
+// WithValue 写入请求上下文, 传入 *gin.Context 时写入其 Request 的上下文并返回原 gin 上下文
+func WithValue(ctx context.Context, key, val any) context.Context {
+	return update(ctx, func(v *values) {
+		if v.extra == nil {
+			v.extra = make(map[any]any)
+		}
+		v.extra[key] = val
+	})
+}
+
+// Value 读取请求上下文, 传入 *gin.Context 时从其 Request 的上下文读取
+func Value(ctx context.Context, key any) any {
+	if v := load(ctx); v != nil {
+		return v.extra[key]
+	}
+	return nil
+}
+
+// WithTraceID 写入链路ID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return update(ctx, func(v *values) { v.traceID = traceID })
+}
+
+// TraceID 获取链路ID
+func TraceID(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.traceID
+	}
+	return ""
+}
+
+// WithSpan 写入当前请求的 span 信息, 对应 W3C traceparent 中的 parent-id 和 trace-flags
+func WithSpan(ctx context.Context, spanID, parentSpanID, traceFlags, traceState string) context.Context {
+	return update(ctx, func(v *values) {
+		v.spanID = spanID
+		v.parentSpanID = parentSpanID
+		v.traceFlags = traceFlags
+		v.traceState = traceState
+	})
+}
+
+// SpanID 获取当前 span ID
+func SpanID(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.spanID
+	}
+	return ""
+}
+
+// ParentSpanID 获取上游 span ID, 新建的链路为空
+func ParentSpanID(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.parentSpanID
+	}
+	return ""
+}
+
+// TraceFlags 获取 W3C trace-flags
+func TraceFlags(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.traceFlags
+	}
+	return ""
+}
+
+// TraceState 获取上游传入的 W3C tracestate
+func TraceState(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.traceState
+	}
+	return ""
+}
+
+// WithClientID 写入调用方ID
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return update(ctx, func(v *values) { v.clientID = clientID })
+}
+
+// ClientID 获取调用方ID
+func ClientID(ctx context.Context) string {
+	if v := load(ctx); v != nil {
+		return v.clientID
+	}
+	return ""
+}
+
+// WithStartTime 写入请求开始时间
+func WithStartTime(ctx context.Context, start time.Time) context.Context {
+	return update(ctx, func(v *values) { v.startTime = start })
+}
+
+// StartTime 获取请求开始时间
+func StartTime(ctx context.Context) time.Time {
+	if v := load(ctx); v != nil {
+		return v.startTime
+	}
+	return time.Time{}
+}
+
+// WithDeadline 设置请求截止时间, 传入 *gin.Context 时替换其 Request 的上下文
+func WithDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		deadlineCtx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		c.Request = c.Request.WithContext(deadlineCtx)
+		return c, cancel
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// Deadline 获取请求截止时间
+func Deadline(ctx context.Context) (time.Time, bool) {
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return time.Time{}, false
+		}
+		return c.Request.Context().Deadline()
+	}
+	return ctx.Deadline()
+}
@@ -0,0 +1,129 @@
+package reqctx
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGinContextValues(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	WithTraceID(c, "trace")
+	WithSpan(c, "span", "parent", "01", "k=v")
+	WithClientID(c, "client")
+	start := time.Now()
+	WithStartTime(c, start)
+	WithValue(c, "key", "value")
+
+	// 从 gin 上下文和其 Request 的上下文都能读取
+	for _, ctx := range []context.Context{c, c.Request.Context()} {
+		if got := TraceID(ctx); got != "trace" {
+			t.Errorf("TraceID = %q", got)
+		}
+		if got := SpanID(ctx); got != "span" {
+			t.Errorf("SpanID = %q", got)
+		}
+		if got := ParentSpanID(ctx); got != "parent" {
+			t.Errorf("ParentSpanID = %q", got)
+		}
+		if got := TraceFlags(ctx); got != "01" {
+			t.Errorf("TraceFlags = %q", got)
+		}
+		if got := TraceState(ctx); got != "k=v" {
+			t.Errorf("TraceState = %q", got)
+		}
+		if got := ClientID(ctx); got != "client" {
+			t.Errorf("ClientID = %q", got)
+		}
+		if got := StartTime(ctx); !got.Equal(start) {
+			t.Errorf("StartTime = %v", got)
+		}
+		if got := Value(ctx, "key"); got != "value" {
+			t.Errorf("Value = %v", got)
+		}
+	}
+}
+
+func TestGinContextDoesNotModifyBaseContext(t *testing.T) {
+	base := WithTraceID(context.Background(), "base")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil).WithContext(base)
+
+	if got := TraceID(c); got != "base" {
+		t.Fatalf("应继承上游的值, got %q", got)
+	}
+	WithTraceID(c, "request")
+	if got := TraceID(c); got != "request" {
+		t.Errorf("TraceID = %q", got)
+	}
+	if got := TraceID(base); got != "base" {
+		t.Errorf("不应修改上游上下文, got %q", got)
+	}
+}
+
+func TestContextDoesNotModifyParent(t *testing.T) {
+	parent := WithValue(WithTraceID(context.Background(), "parent"), "key", "parent")
+	child := WithValue(WithTraceID(parent, "child"), "key", "child")
+
+	if got := TraceID(parent); got != "parent" {
+		t.Errorf("parent TraceID = %q", got)
+	}
+	if got := Value(parent, "key"); got != "parent" {
+		t.Errorf("parent Value = %v", got)
+	}
+	if got := TraceID(child); got != "child" {
+		t.Errorf("child TraceID = %q", got)
+	}
+	if got := Value(child, "key"); got != "child" {
+		t.Errorf("child Value = %v", got)
+	}
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("空上下文 TraceID = %q", got)
+	}
+}
+
+// 派生出的上下文交给其他协程读取时, 请求继续写入不影响已派生的上下文, 需配合 -race 运行
+func TestGinContextDerivedContextIsSnapshot(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	WithClientID(c, "client")
+	WithValue(c, "key", "value")
+	derived, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			if got := ClientID(derived); got != "client" {
+				t.Errorf("ClientID = %q", got)
+				return
+			}
+			if got := Value(derived, "key"); got != "value" {
+				t.Errorf("Value = %v", got)
+				return
+			}
+		}
+	}()
+	for i := range 1000 {
+		WithClientID(c, fmt.Sprintf("client-%d", i))
+		WithValue(c, "key", i)
+		WithValue(c, i, i)
+	}
+	wg.Wait()
+
+	if got := ClientID(c); got != "client-999" {
+		t.Errorf("ClientID = %q", got)
+	}
+	if got := Value(c, "key"); got != 999 {
+		t.Errorf("Value = %v", got)
+	}
+}
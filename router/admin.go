@@ -0,0 +1,57 @@
+package router
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"service/controller"
+	"service/metrics"
+	"service/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin 管理接口, 与 /api 分组隔离并使用独立鉴权
+func Admin(r *gin.Engine) {
+
+	admin := r.Group("/admin", middleware.AdminAuth())
+
+	// 实例化控制器
+	adminController := controller.NewAdminController()
+	{
+		admin.GET("/config", adminController.Config)
+		admin.GET("/log/level", adminController.GetLogLevel)
+		admin.PUT("/log/level", adminController.SetLogLevel)
+		admin.DELETE("/log/level", adminController.ResetLogLevel)
+	}
+
+}
+
+// AdminListener 管理端口的路由, 与业务端口分开监听, public 为业务端口的路由
+// /metrics 供内网采集指标, /readyz 返回带失败原因的就绪检查, /debug 下的运行时接口需要管理令牌
+func AdminListener(r *gin.Engine, public *gin.Engine) *gin.Engine {
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/readyz", controller.NewHealthController().ReadyzDetail)
+
+	debug := r.Group("/debug", middleware.AdminAuth())
+
+	debugController := controller.NewDebugController(public)
+	{
+		debug.GET("/goroutines", debugController.Goroutines)
+		debug.GET("/routes", debugController.Routes)
+		debug.GET("/build", debugController.BuildInfo)
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
+	}
+
+	// pprof, 其余 profile 如 heap、allocs、goroutine 由 Index 按名称处理
+	profile := debug.Group("/pprof")
+	{
+		profile.GET("/", gin.WrapF(pprof.Index))
+		profile.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		profile.GET("/profile", gin.WrapF(pprof.Profile))
+		profile.GET("/symbol", gin.WrapF(pprof.Symbol))
+		profile.POST("/symbol", gin.WrapF(pprof.Symbol))
+		profile.GET("/trace", gin.WrapF(pprof.Trace))
+		profile.GET("/:name", gin.WrapF(pprof.Index))
+	}
+	return r
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"service/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health 存活、就绪检查与版本信息, 不需要鉴权
+// 需在注册全局中间件之前调用, 探测请求不经过限流、日志等中间件
+func Health(r *gin.Engine) {
+
+	healthController := controller.NewHealthController()
+	{
+		r.GET("/healthz", healthController.Healthz)
+		r.GET("/readyz", healthController.Readyz)
+	}
+
+	versionController := controller.NewVersionController()
+	r.GET("/version", versionController.Version)
+
+}
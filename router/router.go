@@ -3,7 +3,8 @@ package router
 import "github.com/gin-gonic/gin"
 
 func Route(r *gin.Engine) *gin.Engine {
-	// 装载路由
+	// 装载路由, 存活、就绪检查已在注册中间件之前通过 Health 装载
 	Api(r)
+	Admin(r)
 	return r
 }
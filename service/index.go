@@ -23,7 +23,7 @@ func (s *IndexService) SaveRedisData(ctx context.Context, value model.RedisData)
 		"code": 200,
 	}
 
-	client, err := redis.GetRedisClient("default", 0)
+	client, err := redis.GetUniversalClient("default", 0)
 	if err != nil {
 		errMsg := "redis client error"
 		logger.Error(ctx, errMsg, zap.Error(err))
@@ -51,7 +51,7 @@ func (s *IndexService) SaveRedisData(ctx context.Context, value model.RedisData)
 }
 
 func (s *IndexService) GetRedisData(ctx context.Context, id string) model.RedisData {
-	client, err := redis.GetRedisClient("default", 0)
+	client, err := redis.GetUniversalClient("default", 0)
 	if err != nil {
 		errMsg := "redis client error"
 		logger.Error(ctx, errMsg, zap.Error(err))
@@ -0,0 +1,68 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"service/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultStageTimeout 未指定超时的阶段默认超时时间
+const DefaultStageTimeout = 5 * time.Second
+
+// Stage 关闭阶段
+type Stage struct {
+	Name    string                          // 阶段名称
+	Timeout time.Duration                   // 超时时间, 为0时使用 DefaultStageTimeout
+	Run     func(ctx context.Context) error // 关闭逻辑, 需在 ctx 结束后尽快返回
+}
+
+// Orchestrator 按注册顺序依次执行关闭阶段, 单个阶段失败或超时不影响后续阶段
+type Orchestrator struct {
+	stages []Stage
+}
+
+func New() *Orchestrator {
+	return &Orchestrator{}
+}
+
+// Add 追加关闭阶段
+func (o *Orchestrator) Add(name string, timeout time.Duration, run func(ctx context.Context) error) {
+	o.stages = append(o.stages, Stage{Name: name, Timeout: timeout, Run: run})
+}
+
+// Shutdown 依次执行全部阶段并记录每个阶段的耗时, 返回所有阶段的错误
+// ctx 仅用于记录日志, 各阶段的超时从阶段开始时计算
+func (o *Orchestrator) Shutdown(ctx context.Context) error {
+	var errs []error
+	start := time.Now()
+	for _, stage := range o.stages {
+		if err := o.run(ctx, stage); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", stage.Name, err))
+		}
+	}
+	logger.Info(ctx, "服务关闭完成", zap.Duration("cost", time.Since(start)))
+	return errors.Join(errs...)
+}
+
+func (o *Orchestrator) run(ctx context.Context, stage Stage) error {
+	timeout := stage.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStageTimeout
+	}
+	stageCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := stage.Run(stageCtx)
+	cost := time.Since(start)
+	if err != nil {
+		logger.Error(ctx, "关闭阶段失败: "+stage.Name, zap.Duration("cost", cost), zap.Error(err))
+		return err
+	}
+	logger.Info(ctx, "关闭阶段完成: "+stage.Name, zap.Duration("cost", cost))
+	return nil
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"service/config"
+	"sync"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExporterFactory 根据配置创建 span 导出器
+type ExporterFactory func(cfg config.Tracing) (sdktrace.SpanExporter, error)
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]ExporterFactory{
+		"otlp":   newOTLPExporter,
+		"stdout": newStdoutExporter,
+		"file":   newFileExporter,
+	}
+	exporterFile *os.File
+)
+
+// RegisterExporter 注册导出器, 需在 InitTracing 之前注册
+func RegisterExporter(name string, factory ExporterFactory) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	exporters[name] = factory
+}
+
+func newExporter(cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	exportersMu.RLock()
+	factory, ok := exporters[cfg.Exporter]
+	exportersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的链路追踪导出器: %s", cfg.Exporter)
+	}
+	exporter, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪导出器 %s 失败: %w", cfg.Exporter, err)
+	}
+	return exporter, nil
+}
+
+// newOTLPExporter 通过 OTLP/HTTP 导出
+func newOTLPExporter(cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(context.Background(), opts...)
+}
+
+// newStdoutExporter 输出到控制台
+func newStdoutExporter(config.Tracing) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New()
+}
+
+// newFileExporter 追加写入文件, 每个 span 一行 JSON
+func newFileExporter(cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exporterFile = f
+	return stdouttrace.New(stdouttrace.WithWriter(f))
+}
+
+func closeExporterFile() error {
+	if exporterFile == nil {
+		return nil
+	}
+	return exporterFile.Close()
+}
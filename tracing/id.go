@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type presetIDsKey struct{}
+
+// presetIDs 创建服务端 span 时预设的ID, 来自 Trace 中间件
+type presetIDs struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+// idGenerator 优先使用预设ID, 否则随机生成
+type idGenerator struct{}
+
+func (g *idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	ids, _ := ctx.Value(presetIDsKey{}).(presetIDs)
+	traceID := ids.traceID
+	if !traceID.IsValid() {
+		_, _ = rand.Read(traceID[:])
+	}
+	return traceID, g.NewSpanID(ctx, traceID)
+}
+
+func (g *idGenerator) NewSpanID(ctx context.Context, _ trace.TraceID) trace.SpanID {
+	ids, _ := ctx.Value(presetIDsKey{}).(presetIDs)
+	spanID := ids.spanID
+	if !spanID.IsValid() {
+		_, _ = rand.Read(spanID[:])
+	}
+	return spanID
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"service/config"
+	"service/reqctx"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const defaultServiceName = "service"
+
+var (
+	enabled  bool
+	tracer   trace.Tracer = noop.NewTracerProvider().Tracer(defaultServiceName)
+	provider *sdktrace.TracerProvider
+	once     sync.Once
+)
+
+// InitTracing 按配置初始化链路追踪, 未开启时使用空实现
+func InitTracing() error {
+	var err error
+	once.Do(func() {
+		cfg := config.Get().Tracing
+		if !cfg.Enabled {
+			return
+		}
+		var exporter sdktrace.SpanExporter
+		exporter, err = newExporter(cfg)
+		if err != nil {
+			return
+		}
+
+		name := cfg.ServiceName
+		if name == "" {
+			name = defaultServiceName
+		}
+		ratio := cfg.SampleRatio
+		if ratio == 0 {
+			ratio = 1
+		}
+		provider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+			sdktrace.WithIDGenerator(&idGenerator{}),
+			sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name))),
+		)
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		tracer = provider.Tracer(name)
+		enabled = true
+	})
+	return err
+}
+
+// Enabled 是否开启链路追踪
+func Enabled() bool {
+	return enabled
+}
+
+// Tracer 获取 tracer, 未开启时为空实现
+func Tracer() trace.Tracer {
+	return tracer
+}
+
+// Shutdown 导出剩余的 span 并关闭导出器
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	if err := provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("关闭链路追踪失败: %w", err)
+	}
+	return closeExporterFile()
+}
+
+// StartServerSpan 为请求创建服务端 span
+// 链路ID、上游 span 和 span ID 沿用 Trace 中间件写入 reqctx 的值, 保证日志、响应头与导出的 span 一致
+func StartServerSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	start := ctx
+	if parent, ok := remoteParent(ctx); ok {
+		start = trace.ContextWithRemoteSpanContext(start, parent)
+	}
+	ids := presetIDs{}
+	ids.traceID, _ = trace.TraceIDFromHex(reqctx.TraceID(ctx))
+	ids.spanID, _ = trace.SpanIDFromHex(reqctx.SpanID(ctx))
+	start = context.WithValue(start, presetIDsKey{}, ids)
+
+	opts = append(opts, trace.WithSpanKind(trace.SpanKindServer))
+	_, span := tracer.Start(start, name, opts...)
+	// 预设ID只对服务端 span 生效, 返回不含预设ID的上下文, 避免子 span 复用
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// remoteParent 根据 reqctx 中的上游链路构造远程父 span
+func remoteParent(ctx context.Context) (trace.SpanContext, bool) {
+	traceID, err := trace.TraceIDFromHex(reqctx.TraceID(ctx))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(reqctx.ParentSpanID(ctx))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	cfg := trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, Remote: true}
+	if flags, err := hex.DecodeString(reqctx.TraceFlags(ctx)); err == nil && len(flags) == 1 {
+		cfg.TraceFlags = trace.TraceFlags(flags[0])
+	}
+	if state, err := trace.ParseTraceState(reqctx.TraceState(ctx)); err == nil {
+		cfg.TraceState = state
+	}
+	return trace.NewSpanContext(cfg), true
+}
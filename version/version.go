@@ -0,0 +1,82 @@
+// Package version 编译信息, 编译时通过 -ldflags 注入, 未注入的字段从 runtime/debug.ReadBuildInfo 补全:
+//
+//	go build -ldflags "-X service/version.Version=v1.2.0 -X service/version.Commit=$(git rev-parse HEAD) -X service/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// 编译时注入
+var (
+	Version   string // 版本号
+	Commit    string // 提交
+	BuildTime string // 编译时间
+)
+
+// Info 编译信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Dirty     bool   `json:"dirty"` // 编译时工作区是否有未提交的修改
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get 获取编译信息
+func Get() Info {
+	once.Do(func() {
+		info = Info{
+			Version:   Version,
+			Commit:    Commit,
+			BuildTime: BuildTime,
+			GoVersion: runtime.Version(),
+		}
+		build, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, s := range build.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				// 未注入编译时间时以提交时间代替
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Dirty = s.Value == "true"
+			}
+		}
+		if info.Version == "" {
+			info.Version = "dev"
+		}
+	})
+	return info
+}
+
+// String 单行输出, 用于 --version
+func (i Info) String() string {
+	commit := i.Commit
+	if commit == "" {
+		commit = "unknown"
+	}
+	if i.Dirty {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, commit, i.BuildTime, i.GoVersion)
+}